@@ -5,23 +5,28 @@
 package jsql
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"github.com/xjustloveux/jgo/jcast"
 	"github.com/xjustloveux/jgo/jfile"
 	"reflect"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 )
 
 type Agent struct {
-	db     *sql.DB
-	t      Type
-	tx     *sql.Tx
-	dbName string
+	db          *sql.DB
+	t           Type
+	tx          *Tx
+	dbName      string
+	retry       RetryPolicy
+	loc         *time.Location
+	timeUnit    time.Duration
+	decimalMode DecimalMode
+	decimalFunc func(string) (interface{}, error)
+	nullWrapper bool
 }
 
 // DB returns this Agent *sql.DB
@@ -31,7 +36,10 @@ func (a *Agent) DB() *sql.DB {
 
 // Tx returns this Agent *sql.Tx
 func (a *Agent) Tx() *sql.Tx {
-	return a.tx
+	if a.tx == nil {
+		return nil
+	}
+	return a.tx.tx
 }
 
 // DBType returns this Agent db Type
@@ -44,26 +52,66 @@ func (a *Agent) DbName() string {
 	return a.dbName
 }
 
+// RetryPolicy returns this Agent UseTx retry policy
+func (a *Agent) RetryPolicy() RetryPolicy {
+	return a.retry
+}
+
+// SetRetryPolicy set this Agent UseTx retry policy
+func (a *Agent) SetRetryPolicy(p RetryPolicy) {
+	a.retry = p
+}
+
 // Ping same as sql.DB.Ping
 // if db does not open, will call open before begin
 func (a *Agent) Ping() error {
+	return a.PingContext(context.Background())
+}
+
+// PingContext same as sql.DB.PingContext
+func (a *Agent) PingContext(ctx context.Context) error {
 	if a.db == nil {
 		return errorStr(errorDBNil)
 	}
-	return a.db.Ping()
+	return a.db.PingContext(ctx)
 }
 
 // Begin same as sql.DB.Begin
+// the transaction is kept by this Agent and used by the *Tx functions
 func (a *Agent) Begin() (*sql.Tx, error) {
+	return a.BeginContext(context.Background())
+}
+
+// BeginContext same as sql.DB.BeginTx with default options
+// the provided context is used until the transaction is committed or rolled back
+func (a *Agent) BeginContext(ctx context.Context) (*sql.Tx, error) {
+	if tx, err := a.BeginTxContext(ctx, nil); err != nil {
+		a.tx = nil
+		return nil, err
+	} else {
+		a.tx = tx
+		return tx.tx, nil
+	}
+}
+
+// BeginTx starts a transaction and returns the transaction handle
+// unlike Begin, the transaction is not kept by this Agent,
+// so one Agent can be shared between goroutines that each use their own Tx
+func (a *Agent) BeginTx(opts *sql.TxOptions) (*Tx, error) {
+	return a.BeginTxContext(context.Background(), opts)
+}
+
+// BeginTxContext starts a transaction with context and returns the transaction handle
+// the opts can set the isolation level and read-only, nil is the driver default
+func (a *Agent) BeginTxContext(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
 	if a.db == nil {
 		return nil, errorStr(errorDBNil)
 	}
-	var err error
-	if a.tx, err = a.db.Begin(); err != nil {
-		a.tx = nil
+	if tx, err := a.db.BeginTx(ctx, opts); err != nil {
 		return nil, err
+	} else {
+		return &Tx{agent: a, tx: tx}, nil
 	}
-	return a.tx, nil
 }
 
 // Commit same as sql.Tx.Commit
@@ -97,171 +145,246 @@ func (a *Agent) Rollback() error {
 }
 
 // UseTx start a transaction as a block, return error will roll back, otherwise to commit
+// if a transaction has been begun, the block is nested with a savepoint and only rolls back to it
+// if the error is retryable, the block is retried by the RetryPolicy
 func (a *Agent) UseTx(f func() error) error {
-	if _, err := a.Begin(); err != nil {
-		return err
-	} else {
-		defer func() {
-			if err != nil {
-				if e := a.Rollback(); e != nil {
-					fmt.Println(e)
-				}
-			}
-		}()
-		if err = f(); err != nil {
+	return a.UseTxContext(context.Background(), f)
+}
+
+// UseTxContext start a transaction with context as a block, return error will roll back, otherwise to commit
+// if a transaction has been begun, the block is nested with a savepoint and only rolls back to it
+// if the error is retryable, the block is retried by the RetryPolicy
+func (a *Agent) UseTxContext(ctx context.Context, f func() error) error {
+	if a.tx != nil {
+		return a.tx.UseTxContext(ctx, f)
+	}
+	for attempt := 1; ; attempt++ {
+		err := a.useTx(ctx, f)
+		if !a.retry.retryable(a.t, err, attempt) {
 			return err
 		}
-		if err = a.Commit(); err != nil {
+		delay := a.retry.delay(attempt)
+		retrySubject.Next(RetryEvent{Type: a.t, DbName: a.dbName, Attempt: attempt, Delay: delay, Err: err})
+		if sleepContext(ctx, delay) != nil {
 			return err
 		}
 	}
-	return nil
 }
 
 // Query executes a query that returns Result
 // the id are for xml select tag id
 // the args are for any placeholder parameters in the query, or result struct point
-func (a *Agent) Query(id string, args ...interface{}) (result Result, err error) {
-	var param map[string]interface{}
-	var v interface{}
-	var query string
-	if param, v, err = a.checkArgs(args...); err != nil {
-		return nil, err
-	}
-	if query, args, err = a.xmlAndParamsToQueryAndArgs(Select, id, param); err != nil {
-		return nil, err
-	}
-	if result, err = a.QueryWithSql(query, args...); err != nil {
-		return result, err
-	}
-	if v != nil {
-		m := map[string]interface{}{"Rows": result.Rows()}
-		if err = jfile.Convert(m, v); err != nil {
-			return result, err
-		}
-	}
-	return result, nil
+func (a *Agent) Query(id string, args ...interface{}) (Result, error) {
+	return a.QueryContext(context.Background(), id, args...)
+}
+
+// QueryContext executes a query with context that returns Result
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return a.queryOps(ctx, nil, false, id, args...)
 }
 
 // QueryTx executes a query that returns Result
 // the id are for xml select tag id
 // the args are for any placeholder parameters in the query, or result struct point
-func (a *Agent) QueryTx(id string, args ...interface{}) (result Result, err error) {
-	var param map[string]interface{}
-	var v interface{}
-	var query string
-	if param, v, err = a.checkArgs(args...); err != nil {
-		return nil, err
-	}
-	if query, args, err = a.xmlAndParamsToQueryAndArgs(Select, id, param); err != nil {
+func (a *Agent) QueryTx(id string, args ...interface{}) (Result, error) {
+	return a.QueryTxContext(context.Background(), id, args...)
+}
+
+// QueryTxContext executes a query with context that returns Result
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryTxContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
 		return nil, err
+	} else {
+		return tx.QueryContext(ctx, id, args...)
 	}
-	if result, err = a.QueryTxWithSql(query, args...); err != nil {
-		return result, err
-	}
-	if v != nil {
-		m := map[string]interface{}{"Rows": result.Rows()}
-		if err = jfile.Convert(m, v); err != nil {
-			return result, err
-		}
-	}
-	return result, nil
 }
 
 // QueryWithSql executes a query that returns Result
 func (a *Agent) QueryWithSql(query string, cond ...interface{}) (Result, error) {
-	return a.query(false, query, cond...)
+	return a.QueryWithSqlContext(context.Background(), query, cond...)
+}
+
+// QueryWithSqlContext executes a query with context that returns Result
+func (a *Agent) QueryWithSqlContext(ctx context.Context, query string, cond ...interface{}) (Result, error) {
+	return a.queryRawSql(ctx, nil, false, query, cond...)
 }
 
 // QueryTxWithSql executes a query that returns Result
 func (a *Agent) QueryTxWithSql(query string, cond ...interface{}) (Result, error) {
-	return a.queryTx(false, query, cond...)
+	return a.QueryTxWithSqlContext(context.Background(), query, cond...)
+}
+
+// QueryTxWithSqlContext executes a query with context that returns Result
+func (a *Agent) QueryTxWithSqlContext(ctx context.Context, query string, cond ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryWithSqlContext(ctx, query, cond...)
+	}
 }
 
 // QueryPrepare creates a prepared statement for later queries or executions
-func (a *Agent) QueryPrepare(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.queryPrepareMS(false, id, param, args...)
+func (a *Agent) QueryPrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.QueryPrepareContext(context.Background(), id, param, args...)
+}
+
+// QueryPrepareContext creates a prepared statement with context for later queries or executions
+func (a *Agent) QueryPrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.queryPrepareOps(ctx, nil, false, id, param, args...)
 }
 
 // QueryPrepareTx creates a prepared statement for later queries or executions
-func (a *Agent) QueryPrepareTx(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.queryPrepareTxMS(false, id, param, args...)
+func (a *Agent) QueryPrepareTx(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.QueryPrepareTxContext(context.Background(), id, param, args...)
+}
+
+// QueryPrepareTxContext creates a prepared statement with context for later queries or executions
+func (a *Agent) QueryPrepareTxContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryPrepareContext(ctx, id, param, args...)
+	}
 }
 
 // QueryRow executes a query that is expected to return at most one row
 // the id are for xml select tag id
 // the args are for any placeholder parameters in the query, or result struct point
-func (a *Agent) QueryRow(id string, args ...interface{}) (result Result, err error) {
-	var param map[string]interface{}
-	var v interface{}
-	var query string
-	if param, v, err = a.checkArgs(args...); err != nil {
-		return nil, err
-	}
-	if query, args, err = a.xmlAndParamsToQueryAndArgs(Select, id, param); err != nil {
-		return nil, err
-	}
-	if result, err = a.QueryRowWithSql(query, args...); err != nil {
-		return result, err
-	}
-	if v != nil {
-		m := result.Row()
-		if m == nil {
-			m = make(map[string]interface{})
-		}
-		if err = jfile.Convert(m, v); err != nil {
-			return result, err
-		}
-	}
-	return result, nil
+func (a *Agent) QueryRow(id string, args ...interface{}) (Result, error) {
+	return a.QueryRowContext(context.Background(), id, args...)
+}
+
+// QueryRowContext executes a query with context that is expected to return at most one row
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryRowContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return a.queryOps(ctx, nil, true, id, args...)
 }
 
 // QueryRowTx executes a query that is expected to return at most one row
 // the id are for xml select tag id
 // the args are for any placeholder parameters in the query, or result struct point
-func (a *Agent) QueryRowTx(id string, args ...interface{}) (result Result, err error) {
-	var param map[string]interface{}
-	var v interface{}
-	var query string
-	if param, v, err = a.checkArgs(args...); err != nil {
-		return nil, err
-	}
-	if query, args, err = a.xmlAndParamsToQueryAndArgs(Select, id, param); err != nil {
+func (a *Agent) QueryRowTx(id string, args ...interface{}) (Result, error) {
+	return a.QueryRowTxContext(context.Background(), id, args...)
+}
+
+// QueryRowTxContext executes a query with context that is expected to return at most one row
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryRowTxContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
 		return nil, err
+	} else {
+		return tx.QueryRowContext(ctx, id, args...)
 	}
-	if result, err = a.QueryRowTxWithSql(query, args...); err != nil {
-		return result, err
-	}
-	if v != nil {
-		m := result.Row()
-		if m == nil {
-			m = make(map[string]interface{})
-		}
-		if err = jfile.Convert(m, v); err != nil {
-			return result, err
-		}
-	}
-	return result, nil
 }
 
 // QueryRowWithSql executes a query that is expected to return at most one row
 func (a *Agent) QueryRowWithSql(query string, cond ...interface{}) (Result, error) {
-	return a.query(true, query, cond...)
+	return a.QueryRowWithSqlContext(context.Background(), query, cond...)
+}
+
+// QueryRowWithSqlContext executes a query with context that is expected to return at most one row
+func (a *Agent) QueryRowWithSqlContext(ctx context.Context, query string, cond ...interface{}) (Result, error) {
+	return a.queryRawSql(ctx, nil, true, query, cond...)
 }
 
 // QueryRowTxWithSql executes a query that is expected to return at most one row
 func (a *Agent) QueryRowTxWithSql(query string, cond ...interface{}) (Result, error) {
-	return a.queryTx(true, query, cond...)
+	return a.QueryRowTxWithSqlContext(context.Background(), query, cond...)
+}
+
+// QueryRowTxWithSqlContext executes a query with context that is expected to return at most one row
+func (a *Agent) QueryRowTxWithSqlContext(ctx context.Context, query string, cond ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryRowWithSqlContext(ctx, query, cond...)
+	}
 }
 
 // QueryRowPrepare creates a prepared statement for later queries or executions
-func (a *Agent) QueryRowPrepare(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.queryPrepareMS(true, id, param, args...)
+func (a *Agent) QueryRowPrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.QueryRowPrepareContext(context.Background(), id, param, args...)
+}
+
+// QueryRowPrepareContext creates a prepared statement with context for later queries or executions
+func (a *Agent) QueryRowPrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.queryPrepareOps(ctx, nil, true, id, param, args...)
 }
 
 // QueryRowPrepareTx creates a prepared statement for later queries or executions
-func (a *Agent) QueryRowPrepareTx(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.queryPrepareTxMS(true, id, param, args...)
+func (a *Agent) QueryRowPrepareTx(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.QueryRowPrepareTxContext(context.Background(), id, param, args...)
+}
+
+// QueryRowPrepareTxContext creates a prepared statement with context for later queries or executions
+func (a *Agent) QueryRowPrepareTxContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryRowPrepareContext(ctx, id, param, args...)
+	}
+}
+
+// QueryIter executes a query that returns Rows to read one row at a time
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query
+func (a *Agent) QueryIter(id string, args ...interface{}) (*Rows, error) {
+	return a.QueryIterContext(context.Background(), id, args...)
+}
+
+// QueryIterContext executes a query with context that returns Rows to read one row at a time
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query
+func (a *Agent) QueryIterContext(ctx context.Context, id string, args ...interface{}) (*Rows, error) {
+	return a.queryIterOps(ctx, nil, id, args...)
+}
+
+// QueryIterTx executes a query that returns Rows to read one row at a time
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query
+func (a *Agent) QueryIterTx(id string, args ...interface{}) (*Rows, error) {
+	return a.QueryIterTxContext(context.Background(), id, args...)
+}
+
+// QueryIterTxContext executes a query with context that returns Rows to read one row at a time
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query
+func (a *Agent) QueryIterTxContext(ctx context.Context, id string, args ...interface{}) (*Rows, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryIterContext(ctx, id, args...)
+	}
+}
+
+// QueryIterWithSql executes a query that returns Rows to read one row at a time
+func (a *Agent) QueryIterWithSql(query string, cond ...interface{}) (*Rows, error) {
+	return a.QueryIterWithSqlContext(context.Background(), query, cond...)
+}
+
+// QueryIterWithSqlContext executes a query with context that returns Rows to read one row at a time
+func (a *Agent) QueryIterWithSqlContext(ctx context.Context, query string, cond ...interface{}) (*Rows, error) {
+	return a.queryIterRawSql(ctx, nil, query, cond...)
+}
+
+// QueryIterTxWithSql executes a query that returns Rows to read one row at a time
+func (a *Agent) QueryIterTxWithSql(query string, cond ...interface{}) (*Rows, error) {
+	return a.QueryIterTxWithSqlContext(context.Background(), query, cond...)
+}
+
+// QueryIterTxWithSqlContext executes a query with context that returns Rows to read one row at a time
+func (a *Agent) QueryIterTxWithSqlContext(ctx context.Context, query string, cond ...interface{}) (*Rows, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryIterWithSqlContext(ctx, query, cond...)
+	}
 }
 
 // QueryPage executes a query that returns Result
@@ -269,7 +392,15 @@ func (a *Agent) QueryRowPrepareTx(id string, param map[string]interface{}, args
 // the start and end are for query start row and end row
 // the args are for any placeholder parameters in the query, or result struct point
 func (a *Agent) QueryPage(id string, start, end int64, args ...interface{}) (Result, error) {
-	return a.queryPage(true, id, start, end, args...)
+	return a.QueryPageContext(context.Background(), id, start, end, args...)
+}
+
+// QueryPageContext executes a query with context that returns Result
+// the id are for xml select tag id
+// the start and end are for query start row and end row
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryPageContext(ctx context.Context, id string, start, end int64, args ...interface{}) (Result, error) {
+	return a.queryPage(ctx, nil, id, start, end, args...)
 }
 
 // QueryPageTx executes a query that returns Result
@@ -277,240 +408,806 @@ func (a *Agent) QueryPage(id string, start, end int64, args ...interface{}) (Res
 // the start and end are for query start row and end row
 // the args are for any placeholder parameters in the query, or result struct point
 func (a *Agent) QueryPageTx(id string, start, end int64, args ...interface{}) (Result, error) {
-	return a.queryPage(false, id, start, end, args...)
+	return a.QueryPageTxContext(context.Background(), id, start, end, args...)
+}
+
+// QueryPageTxContext executes a query with context that returns Result
+// the id are for xml select tag id
+// the start and end are for query start row and end row
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryPageTxContext(ctx context.Context, id string, start, end int64, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryPageContext(ctx, id, start, end, args...)
+	}
 }
 
 // QueryPageWithSql executes a query that returns Result
 // the start and end are for query start row and end row
 func (a *Agent) QueryPageWithSql(query, order string, start, end int64, args ...interface{}) (Result, error) {
-	pageQuery, countQuery := getPageSql(a.t, query, order, start, end)
-	return a.queryPageWithSql(true, pageQuery, countQuery, start, end, args...)
+	return a.QueryPageWithSqlContext(context.Background(), query, order, start, end, args...)
+}
+
+// QueryPageWithSqlContext executes a query with context that returns Result
+// the start and end are for query start row and end row
+func (a *Agent) QueryPageWithSqlContext(ctx context.Context, query, order string, start, end int64, args ...interface{}) (Result, error) {
+	return a.queryPageRawSql(ctx, nil, query, order, start, end, args...)
 }
 
 // QueryPageTxWithSql executes a query that returns Result
 // the start and end are for query start row and end row
 func (a *Agent) QueryPageTxWithSql(query, order string, start, end int64, args ...interface{}) (Result, error) {
-	pageQuery, countQuery := getPageSql(a.t, query, order, start, end)
-	return a.queryPageWithSql(false, pageQuery, countQuery, start, end, args...)
+	return a.QueryPageTxWithSqlContext(context.Background(), query, order, start, end, args...)
 }
 
-// QuerySqlAndArgs returns query sql and args
-func (a *Agent) QuerySqlAndArgs(id string, args ...interface{}) (string, []interface{}, error) {
-	return a.getSqlAndArgs(Select, id, args...)
+// QueryPageTxWithSqlContext executes a query with context that returns Result
+// the start and end are for query start row and end row
+func (a *Agent) QueryPageTxWithSqlContext(ctx context.Context, query, order string, start, end int64, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryPageWithSqlContext(ctx, query, order, start, end, args...)
+	}
 }
 
-// Count return query count
-func (a *Agent) Count(id string, args ...interface{}) (count int, err error) {
-	var query string
-	if query, args, err = a.QuerySqlAndArgs(id, args...); err != nil {
-		return 0, err
-	}
-	if err = a.queryRowScan(query, &count, args...); err != nil {
-		return 0, err
-	}
-	return
+// QueryPaging executes a page number query that returns Result with PageInfo
+// the id are for xml select tag id
+// the p is the page number and page size
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryPaging(id string, p Paging, args ...interface{}) (Result, error) {
+	return a.QueryPagingContext(context.Background(), id, p, args...)
 }
 
-// CountTx return query count
-func (a *Agent) CountTx(id string, args ...interface{}) (count int, err error) {
-	var query string
-	if query, args, err = a.QuerySqlAndArgs(id, args...); err != nil {
-		return 0, err
-	}
-	if err = a.queryRowScanTx(query, &count, args...); err != nil {
-		return 0, err
-	}
-	return
+// QueryPagingContext executes a page number query with context that returns Result with PageInfo
+// the id are for xml select tag id
+// the p is the page number and page size
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryPagingContext(ctx context.Context, id string, p Paging, args ...interface{}) (Result, error) {
+	return a.queryPaging(ctx, nil, id, p, args...)
 }
 
-// Exists return query sql exists data
-func (a *Agent) Exists(id string, args ...interface{}) (exists bool, err error) {
-	var query string
-	if query, args, err = a.QuerySqlAndArgs(id, args...); err != nil {
-		return false, err
-	}
-	var e string
-	query = getExistsSql(a.t, query)
-	if err = a.queryRowScan(query, &e, args...); err != nil {
-		return false, err
-	}
-	exists = e == "Y"
-	return
+// QueryPagingTx executes a page number query that returns Result with PageInfo
+// the id are for xml select tag id
+// the p is the page number and page size
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryPagingTx(id string, p Paging, args ...interface{}) (Result, error) {
+	return a.QueryPagingTxContext(context.Background(), id, p, args...)
 }
 
-// ExistsTx return query sql exists data
-func (a *Agent) ExistsTx(id string, args ...interface{}) (exists bool, err error) {
-	var query string
-	if query, args, err = a.QuerySqlAndArgs(id, args...); err != nil {
-		return false, err
-	}
-	var e string
-	query = getExistsSql(a.t, query)
-	if err = a.queryRowScanTx(query, &e, args...); err != nil {
-		return false, err
+// QueryPagingTxContext executes a page number query with context that returns Result with PageInfo
+// the id are for xml select tag id
+// the p is the page number and page size
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryPagingTxContext(ctx context.Context, id string, p Paging, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryPagingContext(ctx, id, p, args...)
 	}
-	exists = e == "Y"
-	return
 }
 
-// ExecWithSql executes a query with db.Exec
-func (a *Agent) ExecWithSql(query string, cond ...interface{}) (Result, error) {
-	return a.exec(query, cond...)
+// QueryPagingWithSql executes a page number query that returns Result with PageInfo
+// the p is the page number and page size
+func (a *Agent) QueryPagingWithSql(query, order string, p Paging, args ...interface{}) (Result, error) {
+	return a.QueryPagingWithSqlContext(context.Background(), query, order, p, args...)
 }
 
-// ExecTxWithSql executes a query with tx.Exec
-func (a *Agent) ExecTxWithSql(query string, cond ...interface{}) (Result, error) {
-	return a.execTx(query, cond...)
+// QueryPagingWithSqlContext executes a page number query with context that returns Result with PageInfo
+// the p is the page number and page size
+func (a *Agent) QueryPagingWithSqlContext(ctx context.Context, query, order string, p Paging, args ...interface{}) (Result, error) {
+	return a.queryPagingRawSql(ctx, nil, query, order, p, args...)
 }
 
-// Insert executes a query with db.Exec
-func (a *Agent) Insert(id string, args ...interface{}) (result Result, err error) {
-	return a.execOps(Insert, id, args...)
+// QueryPagingTxWithSql executes a page number query that returns Result with PageInfo
+// the p is the page number and page size
+func (a *Agent) QueryPagingTxWithSql(query, order string, p Paging, args ...interface{}) (Result, error) {
+	return a.QueryPagingTxWithSqlContext(context.Background(), query, order, p, args...)
 }
 
-// InsertTx executes a query with tx.Exec
-func (a *Agent) InsertTx(id string, args ...interface{}) (result Result, err error) {
-	return a.execTxOps(Insert, id, args...)
+// QueryPagingTxWithSqlContext executes a page number query with context that returns Result with PageInfo
+// the p is the page number and page size
+func (a *Agent) QueryPagingTxWithSqlContext(ctx context.Context, query, order string, p Paging, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryPagingWithSqlContext(ctx, query, order, p, args...)
+	}
 }
 
-// InsertPrepare creates a prepared statement for later queries or executions
-func (a *Agent) InsertPrepare(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.execPrepareOps(Insert, id, param, args...)
+// QueryKeyset executes a keyset pagination query that returns Result
+// the id are for xml select tag id
+// the k is the cursor of the page, the ORDER BY of the select tag is replaced by the k.Column
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryKeyset(id string, k Keyset, args ...interface{}) (Result, error) {
+	return a.QueryKeysetContext(context.Background(), id, k, args...)
 }
 
-// InsertPrepareTx creates a prepared statement for later queries or executions
-func (a *Agent) InsertPrepareTx(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.execPrepareTxOps(Insert, id, param, args...)
+// QueryKeysetContext executes a keyset pagination query with context that returns Result
+// the id are for xml select tag id
+// the k is the cursor of the page, the ORDER BY of the select tag is replaced by the k.Column
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryKeysetContext(ctx context.Context, id string, k Keyset, args ...interface{}) (Result, error) {
+	return a.queryKeyset(ctx, nil, id, k, args...)
 }
 
-// InsertSqlAndArgs returns insert sql and args
-func (a *Agent) InsertSqlAndArgs(id string, args ...interface{}) (string, []interface{}, error) {
-	return a.getSqlAndArgs(Insert, id, args...)
+// QueryKeysetTx executes a keyset pagination query that returns Result
+// the id are for xml select tag id
+// the k is the cursor of the page, the ORDER BY of the select tag is replaced by the k.Column
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryKeysetTx(id string, k Keyset, args ...interface{}) (Result, error) {
+	return a.QueryKeysetTxContext(context.Background(), id, k, args...)
 }
 
-// InsertWithLastInsertId return last insert id by QueryRow.Scan
-func (a *Agent) InsertWithLastInsertId(id string, args ...interface{}) (lastInsertId int, err error) {
-	var query string
-	if query, args, err = a.InsertSqlAndArgs(id, args...); err != nil {
-		return 0, err
-	}
-	if err = a.queryRowScan(query, &lastInsertId, args...); err != nil {
-		return 0, err
-	}
-	return
+// QueryKeysetTxContext executes a keyset pagination query with context that returns Result
+// the id are for xml select tag id
+// the k is the cursor of the page, the ORDER BY of the select tag is replaced by the k.Column
+// the args are for any placeholder parameters in the query, or result struct point
+func (a *Agent) QueryKeysetTxContext(ctx context.Context, id string, k Keyset, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryKeysetContext(ctx, id, k, args...)
+	}
 }
 
-// InsertTxWithLastInsertId return last insert id by QueryRow.Scan
-func (a *Agent) InsertTxWithLastInsertId(id string, args ...interface{}) (lastInsertId int, err error) {
-	var query string
-	if query, args, err = a.InsertSqlAndArgs(id, args...); err != nil {
+// QueryKeysetWithSql executes a keyset pagination query that returns Result
+// the k is the cursor of the page, the query should not contain ORDER BY
+func (a *Agent) QueryKeysetWithSql(query string, k Keyset, args ...interface{}) (Result, error) {
+	return a.QueryKeysetWithSqlContext(context.Background(), query, k, args...)
+}
+
+// QueryKeysetWithSqlContext executes a keyset pagination query with context that returns Result
+// the k is the cursor of the page, the query should not contain ORDER BY
+func (a *Agent) QueryKeysetWithSqlContext(ctx context.Context, query string, k Keyset, args ...interface{}) (Result, error) {
+	return a.queryKeysetRawSql(ctx, nil, query, k, args...)
+}
+
+// QueryKeysetTxWithSql executes a keyset pagination query that returns Result
+// the k is the cursor of the page, the query should not contain ORDER BY
+func (a *Agent) QueryKeysetTxWithSql(query string, k Keyset, args ...interface{}) (Result, error) {
+	return a.QueryKeysetTxWithSqlContext(context.Background(), query, k, args...)
+}
+
+// QueryKeysetTxWithSqlContext executes a keyset pagination query with context that returns Result
+// the k is the cursor of the page, the query should not contain ORDER BY
+func (a *Agent) QueryKeysetTxWithSqlContext(ctx context.Context, query string, k Keyset, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.QueryKeysetWithSqlContext(ctx, query, k, args...)
+	}
+}
+
+// QuerySqlAndArgs returns query sql and args
+func (a *Agent) QuerySqlAndArgs(id string, args ...interface{}) (string, []interface{}, error) {
+	_, query, args, err := a.getSqlAndArgs(Select, id, args...)
+	return query, args, err
+}
+
+// Count return query count
+func (a *Agent) Count(id string, args ...interface{}) (int, error) {
+	return a.CountContext(context.Background(), id, args...)
+}
+
+// CountContext return query count with context
+func (a *Agent) CountContext(ctx context.Context, id string, args ...interface{}) (int, error) {
+	return a.count(ctx, nil, id, args...)
+}
+
+// CountTx return query count
+func (a *Agent) CountTx(id string, args ...interface{}) (int, error) {
+	return a.CountTxContext(context.Background(), id, args...)
+}
+
+// CountTxContext return query count with context
+func (a *Agent) CountTxContext(ctx context.Context, id string, args ...interface{}) (int, error) {
+	if tx, err := a.currentTx(); err != nil {
 		return 0, err
+	} else {
+		return tx.CountContext(ctx, id, args...)
 	}
-	if err = a.queryRowScanTx(query, &lastInsertId, args...); err != nil {
+}
+
+// Exists return query sql exists data
+func (a *Agent) Exists(id string, args ...interface{}) (bool, error) {
+	return a.ExistsContext(context.Background(), id, args...)
+}
+
+// ExistsContext return query sql exists data with context
+func (a *Agent) ExistsContext(ctx context.Context, id string, args ...interface{}) (bool, error) {
+	return a.exists(ctx, nil, id, args...)
+}
+
+// ExistsTx return query sql exists data
+func (a *Agent) ExistsTx(id string, args ...interface{}) (bool, error) {
+	return a.ExistsTxContext(context.Background(), id, args...)
+}
+
+// ExistsTxContext return query sql exists data with context
+func (a *Agent) ExistsTxContext(ctx context.Context, id string, args ...interface{}) (bool, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return false, err
+	} else {
+		return tx.ExistsContext(ctx, id, args...)
+	}
+}
+
+// ExecWithSql executes a query with db.Exec
+func (a *Agent) ExecWithSql(query string, cond ...interface{}) (Result, error) {
+	return a.ExecWithSqlContext(context.Background(), query, cond...)
+}
+
+// ExecWithSqlContext executes a query with db.ExecContext
+func (a *Agent) ExecWithSqlContext(ctx context.Context, query string, cond ...interface{}) (Result, error) {
+	return a.execRawSql(ctx, nil, query, cond...)
+}
+
+// ExecTxWithSql executes a query with tx.Exec
+func (a *Agent) ExecTxWithSql(query string, cond ...interface{}) (Result, error) {
+	return a.ExecTxWithSqlContext(context.Background(), query, cond...)
+}
+
+// ExecTxWithSqlContext executes a query with tx.ExecContext
+func (a *Agent) ExecTxWithSqlContext(ctx context.Context, query string, cond ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.ExecWithSqlContext(ctx, query, cond...)
+	}
+}
+
+// Insert executes a query with db.Exec
+func (a *Agent) Insert(id string, args ...interface{}) (Result, error) {
+	return a.InsertContext(context.Background(), id, args...)
+}
+
+// InsertContext executes a query with db.ExecContext
+func (a *Agent) InsertContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return a.execOps(ctx, nil, Insert, id, args...)
+}
+
+// InsertTx executes a query with tx.Exec
+func (a *Agent) InsertTx(id string, args ...interface{}) (Result, error) {
+	return a.InsertTxContext(context.Background(), id, args...)
+}
+
+// InsertTxContext executes a query with tx.ExecContext
+func (a *Agent) InsertTxContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.InsertContext(ctx, id, args...)
+	}
+}
+
+// InsertPrepare creates a prepared statement for later queries or executions
+func (a *Agent) InsertPrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.InsertPrepareContext(context.Background(), id, param, args...)
+}
+
+// InsertPrepareContext creates a prepared statement with context for later queries or executions
+func (a *Agent) InsertPrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.execPrepareOps(ctx, nil, Insert, id, param, args...)
+}
+
+// InsertPrepareTx creates a prepared statement for later queries or executions
+func (a *Agent) InsertPrepareTx(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.InsertPrepareTxContext(context.Background(), id, param, args...)
+}
+
+// InsertPrepareTxContext creates a prepared statement with context for later queries or executions
+func (a *Agent) InsertPrepareTxContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.InsertPrepareContext(ctx, id, param, args...)
+	}
+}
+
+// InsertSqlAndArgs returns insert sql and args
+func (a *Agent) InsertSqlAndArgs(id string, args ...interface{}) (string, []interface{}, error) {
+	_, query, args, err := a.getSqlAndArgs(Insert, id, args...)
+	return query, args, err
+}
+
+// InsertWithLastInsertId return last insert id by QueryRow.Scan
+func (a *Agent) InsertWithLastInsertId(id string, args ...interface{}) (int, error) {
+	return a.InsertWithLastInsertIdContext(context.Background(), id, args...)
+}
+
+// InsertWithLastInsertIdContext return last insert id by QueryRowContext.Scan
+func (a *Agent) InsertWithLastInsertIdContext(ctx context.Context, id string, args ...interface{}) (int, error) {
+	return a.insertWithLastInsertId(ctx, nil, id, args...)
+}
+
+// InsertTxWithLastInsertId return last insert id by QueryRow.Scan
+func (a *Agent) InsertTxWithLastInsertId(id string, args ...interface{}) (int, error) {
+	return a.InsertTxWithLastInsertIdContext(context.Background(), id, args...)
+}
+
+// InsertTxWithLastInsertIdContext return last insert id by QueryRowContext.Scan
+func (a *Agent) InsertTxWithLastInsertIdContext(ctx context.Context, id string, args ...interface{}) (int, error) {
+	if tx, err := a.currentTx(); err != nil {
 		return 0, err
+	} else {
+		return tx.InsertWithLastInsertIdContext(ctx, id, args...)
 	}
-	return
 }
 
 // Update executes a query with db.Exec
-func (a *Agent) Update(id string, args ...interface{}) (result Result, err error) {
-	return a.execOps(Update, id, args...)
+func (a *Agent) Update(id string, args ...interface{}) (Result, error) {
+	return a.UpdateContext(context.Background(), id, args...)
+}
+
+// UpdateContext executes a query with db.ExecContext
+func (a *Agent) UpdateContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return a.execOps(ctx, nil, Update, id, args...)
 }
 
 // UpdateTx executes a query with tx.Exec
-func (a *Agent) UpdateTx(id string, args ...interface{}) (result Result, err error) {
-	return a.execTxOps(Update, id, args...)
+func (a *Agent) UpdateTx(id string, args ...interface{}) (Result, error) {
+	return a.UpdateTxContext(context.Background(), id, args...)
+}
+
+// UpdateTxContext executes a query with tx.ExecContext
+func (a *Agent) UpdateTxContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.UpdateContext(ctx, id, args...)
+	}
 }
 
 // UpdatePrepare creates a prepared statement for later queries or executions
-func (a *Agent) UpdatePrepare(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.execPrepareOps(Update, id, param, args...)
+func (a *Agent) UpdatePrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.UpdatePrepareContext(context.Background(), id, param, args...)
+}
+
+// UpdatePrepareContext creates a prepared statement with context for later queries or executions
+func (a *Agent) UpdatePrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.execPrepareOps(ctx, nil, Update, id, param, args...)
 }
 
 // UpdatePrepareTx creates a prepared statement for later queries or executions
-func (a *Agent) UpdatePrepareTx(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.execPrepareTxOps(Update, id, param, args...)
+func (a *Agent) UpdatePrepareTx(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.UpdatePrepareTxContext(context.Background(), id, param, args...)
+}
+
+// UpdatePrepareTxContext creates a prepared statement with context for later queries or executions
+func (a *Agent) UpdatePrepareTxContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.UpdatePrepareContext(ctx, id, param, args...)
+	}
 }
 
 // UpdateSqlAndArgs returns update sql and args
 func (a *Agent) UpdateSqlAndArgs(id string, args ...interface{}) (string, []interface{}, error) {
-	return a.getSqlAndArgs(Update, id, args...)
+	_, query, args, err := a.getSqlAndArgs(Update, id, args...)
+	return query, args, err
 }
 
 // Delete executes a query with db.Exec
-func (a *Agent) Delete(id string, args ...interface{}) (result Result, err error) {
-	return a.execOps(Delete, id, args...)
+func (a *Agent) Delete(id string, args ...interface{}) (Result, error) {
+	return a.DeleteContext(context.Background(), id, args...)
+}
+
+// DeleteContext executes a query with db.ExecContext
+func (a *Agent) DeleteContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return a.execOps(ctx, nil, Delete, id, args...)
 }
 
 // DeleteTx executes a query with tx.Exec
-func (a *Agent) DeleteTx(id string, args ...interface{}) (result Result, err error) {
-	return a.execTxOps(Delete, id, args...)
+func (a *Agent) DeleteTx(id string, args ...interface{}) (Result, error) {
+	return a.DeleteTxContext(context.Background(), id, args...)
+}
+
+// DeleteTxContext executes a query with tx.ExecContext
+func (a *Agent) DeleteTxContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.DeleteContext(ctx, id, args...)
+	}
 }
 
 // DeletePrepare creates a prepared statement for later queries or executions
-func (a *Agent) DeletePrepare(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.execPrepareOps(Delete, id, param, args...)
+func (a *Agent) DeletePrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.DeletePrepareContext(context.Background(), id, param, args...)
+}
+
+// DeletePrepareContext creates a prepared statement with context for later queries or executions
+func (a *Agent) DeletePrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.execPrepareOps(ctx, nil, Delete, id, param, args...)
 }
 
 // DeletePrepareTx creates a prepared statement for later queries or executions
-func (a *Agent) DeletePrepareTx(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.execPrepareTxOps(Delete, id, param, args...)
+func (a *Agent) DeletePrepareTx(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.DeletePrepareTxContext(context.Background(), id, param, args...)
+}
+
+// DeletePrepareTxContext creates a prepared statement with context for later queries or executions
+func (a *Agent) DeletePrepareTxContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.DeletePrepareContext(ctx, id, param, args...)
+	}
 }
 
 // DeleteSqlAndArgs returns delete sql and args
 func (a *Agent) DeleteSqlAndArgs(id string, args ...interface{}) (string, []interface{}, error) {
-	return a.getSqlAndArgs(Delete, id, args...)
+	_, query, args, err := a.getSqlAndArgs(Delete, id, args...)
+	return query, args, err
 }
 
 // Other executes a query with db.Exec
-func (a *Agent) Other(id string, args ...interface{}) (result Result, err error) {
-	return a.execOps(Other, id, args...)
+func (a *Agent) Other(id string, args ...interface{}) (Result, error) {
+	return a.OtherContext(context.Background(), id, args...)
+}
+
+// OtherContext executes a query with db.ExecContext
+func (a *Agent) OtherContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return a.execOps(ctx, nil, Other, id, args...)
 }
 
 // OtherTx executes a query with tx.Exec
-func (a *Agent) OtherTx(id string, args ...interface{}) (result Result, err error) {
-	return a.execTxOps(Other, id, args...)
+func (a *Agent) OtherTx(id string, args ...interface{}) (Result, error) {
+	return a.OtherTxContext(context.Background(), id, args...)
+}
+
+// OtherTxContext executes a query with tx.ExecContext
+func (a *Agent) OtherTxContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.OtherContext(ctx, id, args...)
+	}
 }
 
 // OtherPrepare creates a prepared statement for later queries or executions
-func (a *Agent) OtherPrepare(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.execPrepareOps(Other, id, param, args...)
+func (a *Agent) OtherPrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.OtherPrepareContext(context.Background(), id, param, args...)
+}
+
+// OtherPrepareContext creates a prepared statement with context for later queries or executions
+func (a *Agent) OtherPrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.execPrepareOps(ctx, nil, Other, id, param, args...)
 }
 
 // OtherPrepareTx creates a prepared statement for later queries or executions
-func (a *Agent) OtherPrepareTx(id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	return a.execPrepareTxOps(Other, id, param, args...)
+func (a *Agent) OtherPrepareTx(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return a.OtherPrepareTxContext(context.Background(), id, param, args...)
+}
+
+// OtherPrepareTxContext creates a prepared statement with context for later queries or executions
+func (a *Agent) OtherPrepareTxContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.OtherPrepareContext(ctx, id, param, args...)
+	}
 }
 
 // OtherSqlAndArgs returns other sql and args
 func (a *Agent) OtherSqlAndArgs(id string, args ...interface{}) (string, []interface{}, error) {
-	return a.getSqlAndArgs(Other, id, args...)
+	_, query, args, err := a.getSqlAndArgs(Other, id, args...)
+	return query, args, err
 }
 
 // Tables returns table name list
 // or you can use args input query statement
 func (a *Agent) Tables(args ...interface{}) ([]string, error) {
+	return a.TablesContext(context.Background(), args...)
+}
+
+// TablesContext returns table name list with context
+// or you can use args input query statement
+func (a *Agent) TablesContext(ctx context.Context, args ...interface{}) ([]string, error) {
+	return a.tables(ctx, nil, args...)
+}
+
+// TablesTx returns table name list
+// or you can use args input query statement
+func (a *Agent) TablesTx(args ...interface{}) ([]string, error) {
+	return a.TablesTxContext(context.Background(), args...)
+}
+
+// TablesTxContext returns table name list with context
+// or you can use args input query statement
+func (a *Agent) TablesTxContext(ctx context.Context, args ...interface{}) ([]string, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.TablesContext(ctx, args...)
+	}
+}
+
+// TableSchema return table schema
+// or you can use args input query statement
+func (a *Agent) TableSchema(table string, args ...interface{}) ([]TableSchema, error) {
+	return a.TableSchemaContext(context.Background(), table, args...)
+}
+
+// TableSchemaContext return table schema with context
+// or you can use args input query statement
+func (a *Agent) TableSchemaContext(ctx context.Context, table string, args ...interface{}) ([]TableSchema, error) {
+	return a.tableSchema(ctx, nil, table, args...)
+}
+
+// TableSchemaTx return table schema
+// or you can use args input query statement
+func (a *Agent) TableSchemaTx(table string, args ...interface{}) ([]TableSchema, error) {
+	return a.TableSchemaTxContext(context.Background(), table, args...)
+}
+
+// TableSchemaTxContext return table schema with context
+// or you can use args input query statement
+func (a *Agent) TableSchemaTxContext(ctx context.Context, table string, args ...interface{}) ([]TableSchema, error) {
+	if tx, err := a.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return tx.TableSchemaContext(ctx, table, args...)
+	}
+}
+
+// useTx runs f in a new transaction started by Begin
+func (a *Agent) useTx(ctx context.Context, f func() error) error {
+	if _, err := a.BeginContext(ctx); err != nil {
+		return err
+	} else {
+		defer func() {
+			if err != nil {
+				if e := a.Rollback(); e != nil {
+					fmt.Println(e)
+				}
+			}
+		}()
+		if err = f(); err != nil {
+			return err
+		}
+		if err = a.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// currentTx returns the transaction started by Begin
+func (a *Agent) currentTx() (*Tx, error) {
+	if a.tx == nil {
+		return nil, errorStr(errorDbNotBegin)
+	}
+	return a.tx, nil
+}
+
+// executor returns tx when it is not nil, otherwise returns db
+func (a *Agent) executor(tx *Tx) (executor, error) {
+	if tx != nil {
+		if tx.tx == nil {
+			return nil, errorStr(errorDbNotBegin)
+		}
+		return tx.tx, nil
+	}
+	if a.db == nil {
+		return nil, errorStr(errorDBNil)
+	}
+	return a.db, nil
+}
+
+func (a *Agent) checkArgs(args ...interface{}) (map[string]interface{}, interface{}, error) {
+	var pm map[string]interface{}
+	var v interface{}
+	for _, arg := range args {
+		switch reflect.TypeOf(arg).Kind() {
+		case reflect.Map:
+			if m, err := jcast.StringMapInterface(arg); err != nil {
+				return nil, nil, err
+			} else {
+				pm = m
+			}
+		case reflect.Struct:
+			var err error
+			var b []byte
+			if b, err = json.Marshal(&arg); err != nil {
+				return nil, nil, err
+			}
+			pm = make(map[string]interface{})
+			if err = jfile.Decode(jfile.Json.String(), b, pm); err != nil {
+				return nil, nil, err
+			}
+			// the json of time.Time is string, keep the time.Time to bind it natively
+			structTimes(reflect.ValueOf(arg), pm)
+		case reflect.Ptr:
+			v = arg
+		}
+	}
+	return pm, v, nil
+}
+
+// jsonTimes returns the json decoded value dv of rv whose time strings are replaced by the time.Time of rv
+func jsonTimes(rv reflect.Value, dv interface{}) interface{} {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return dv
+		}
+		rv = rv.Elem()
+	}
+	if rv.Type() == timeType {
+		return rv.Interface()
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		if m, ok := dv.(map[string]interface{}); ok {
+			structTimes(rv, m)
+		}
+	case reflect.Slice, reflect.Array:
+		if l, ok := dv.([]interface{}); ok && len(l) == rv.Len() {
+			for i := range l {
+				l[i] = jsonTimes(rv.Index(i), l[i])
+			}
+		}
+	case reflect.Map:
+		if m, ok := dv.(map[string]interface{}); ok {
+			for iter := rv.MapRange(); iter.Next(); {
+				k := fmt.Sprint(iter.Key().Interface())
+				if d, ok := m[k]; ok {
+					m[k] = jsonTimes(iter.Value(), d)
+				}
+			}
+		}
+	}
+	return dv
+}
+
+// structTimes replaces the time strings of the json decoded m of the struct rv by the time.Time of rv
+// the fields of the embedded struct without json name are in m as json does
+func structTimes(rv reflect.Value, m map[string]interface{}) {
+	for i := 0; i < rv.NumField(); i++ {
+		sf := rv.Type().Field(i)
+		name := sf.Name
+		if js, ok := sf.Tag.Lookup("json"); ok {
+			if js = strings.Split(js, ",")[0]; js == "-" {
+				continue
+			} else if js != "" {
+				name = js
+			}
+		}
+		fv := rv.Field(i)
+		if sf.Anonymous && name == sf.Name {
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+				structTimes(fv, m)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if d, ok := m[name]; ok {
+			m[name] = jsonTimes(fv, d)
+		}
+	}
+}
+
+func (a *Agent) queryOps(ctx context.Context, tx *Tx, single bool, id string, args ...interface{}) (result Result, err error) {
+	var param map[string]interface{}
+	var v interface{}
+	if param, v, err = a.checkArgs(args...); err != nil {
+		return nil, err
+	}
+	var elem *element
+	var query string
+	if elem, query, args, err = a.xmlAndParamsToQueryAndArgs(Select, id, param); err != nil {
+		return nil, err
+	}
+	ctx, cancel := elem.withTimeout(ctx)
+	defer cancel()
+	if result, err = a.query(ctx, tx, single, query, args...); err != nil {
+		return result, err
+	}
+	if v != nil {
+		if single {
+			err = mapRow(result, v)
+		} else {
+			err = mapRows(result, false, v)
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (a *Agent) queryIterOps(ctx context.Context, tx *Tx, id string, args ...interface{}) (*Rows, error) {
+	if param, _, err := a.checkArgs(args...); err != nil {
+		return nil, err
+	} else {
+		var elem *element
+		var query string
+		if elem, query, args, err = a.xmlAndParamsToQueryAndArgs(Select, id, param); err != nil {
+			return nil, err
+		}
+		ctx, cancel := elem.withTimeout(ctx)
+		return a.queryIter(ctx, cancel, tx, query, args...)
+	}
+}
+
+func (a *Agent) queryPrepareOps(ctx context.Context, tx *Tx, single bool, id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
+	var elem *element
+	var query string
+	if elem, query, args, err = a.prepareQueryAndArgs(Select, id, param, args); err != nil {
+		return nil, err
+	}
+	ctx, cancel := elem.withTimeout(ctx)
+	defer cancel()
+	return a.queryPrepare(ctx, tx, single, query, args...)
+}
+
+func (a *Agent) count(ctx context.Context, tx *Tx, id string, args ...interface{}) (count int, err error) {
+	var elem *element
+	var query string
+	if elem, query, args, err = a.getSqlAndArgs(Select, id, args...); err != nil {
+		return 0, err
+	}
+	ctx, cancel := elem.withTimeout(ctx)
+	defer cancel()
+	if err = a.queryRowScan(ctx, tx, query, &count, args...); err != nil {
+		return 0, err
+	}
+	return
+}
+
+func (a *Agent) exists(ctx context.Context, tx *Tx, id string, args ...interface{}) (exists bool, err error) {
+	var elem *element
+	var query string
+	if elem, query, args, err = a.getSqlAndArgs(Select, id, args...); err != nil {
+		return false, err
+	}
+	ctx, cancel := elem.withTimeout(ctx)
+	defer cancel()
+	var e string
+	query = getExistsSql(a.t, query)
+	if err = a.queryRowScan(ctx, tx, query, &e, args...); err != nil {
+		return false, err
+	}
+	exists = e == "Y"
+	return
+}
+
+func (a *Agent) insertWithLastInsertId(ctx context.Context, tx *Tx, id string, args ...interface{}) (lastInsertId int, err error) {
+	var elem *element
+	var query string
+	if elem, query, args, err = a.getSqlAndArgs(Insert, id, args...); err != nil {
+		return 0, err
+	}
+	ctx, cancel := elem.withTimeout(ctx)
+	defer cancel()
+	if err = a.queryRowScan(ctx, tx, query, &lastInsertId, args...); err != nil {
+		return 0, err
+	}
+	return
+}
+
+func (a *Agent) tables(ctx context.Context, tx *Tx, args ...interface{}) ([]string, error) {
 	query := ""
 	param := make([]interface{}, 0)
 	if len(args) > 0 {
 		query = jcast.String(args[0])
 		param = args[1:]
 	} else {
-		switch a.t {
-		case MySql:
-			query = sqlQueryTablesMySql
-			param = append(param, a.DbName())
-		case MSSql:
-			query = sqlQueryTablesMSSql
-		case Oracle:
-			query = sqlQueryTablesOracle
-		case PostgreSql:
-			query = sqlQueryTablesPostgreSql
-		default:
+		if d := a.t.Dialect(); d != nil {
+			query, param = d.TablesSql(a.DbName())
+		}
+		if query == "" {
 			return nil, errorStr(errorUnknownSqlTypeForAgentTables)
 		}
 	}
-	if result, err := a.QueryWithSql(query, param...); err != nil {
+	if result, err := a.query(ctx, tx, false, query, param...); err != nil {
 		return nil, err
 	} else {
 		list := make([]string, len(result.Rows()))
@@ -529,280 +1226,423 @@ func (a *Agent) Tables(args ...interface{}) ([]string, error) {
 	}
 }
 
-// TablesTx returns table name list
-// or you can use args input query statement
-func (a *Agent) TablesTx(args ...interface{}) ([]string, error) {
+func (a *Agent) tableSchema(ctx context.Context, tx *Tx, table string, args ...interface{}) ([]TableSchema, error) {
 	query := ""
 	param := make([]interface{}, 0)
 	if len(args) > 0 {
 		query = jcast.String(args[0])
 		param = args[1:]
 	} else {
-		switch a.t {
-		case MySql:
-			query = sqlQueryTablesMySql
-			param = append(param, a.DbName())
-		case MSSql:
-			query = sqlQueryTablesMSSql
-		case Oracle:
-			query = sqlQueryTablesOracle
-		case PostgreSql:
-			query = sqlQueryTablesPostgreSql
-		default:
+		if d := a.t.Dialect(); d != nil {
+			query, param = d.TableSchemaSql(a.DbName(), table)
+		}
+		if query == "" {
 			return nil, errorStr(errorUnknownSqlTypeForAgentTables)
 		}
 	}
-	if result, err := a.QueryTxWithSql(query, param...); err != nil {
+	if result, err := a.query(ctx, tx, false, query, param...); err != nil {
 		return nil, err
 	} else {
-		list := make([]string, len(result.Rows()))
+		list := make([]TableSchema, len(result.Rows()))
 		for i, v := range result.Rows() {
-			var m map[string]string
-			if m, err = jcast.StringMapString(v); err != nil {
+			if err = mapTo(v, &list[i]); err != nil {
 				return nil, err
 			}
-			if m["TABLE_NAME"] != "" {
-				list[i] = m["TABLE_NAME"]
-			} else {
-				list[i] = m["table_name"]
-			}
 		}
 		return list, nil
 	}
 }
 
-// TableSchema return table schema
-// or you can use args input query statement
-func (a *Agent) TableSchema(table string, args ...interface{}) ([]TableSchema, error) {
-	query := ""
-	param := make([]interface{}, 0)
-	if len(args) > 0 {
-		query = jcast.String(args[0])
-		param = args[1:]
-	} else {
-		switch a.t {
-		case MySql:
-			query = sqlQueryTableSchemaMySql
-			param = append(param, a.DbName(), table)
-		case MSSql:
-			query = sqlQueryTableSchemaMSSql
-			param = append(param, table)
-		case Oracle:
-			query = sqlQueryTableSchemaOracle
-			param = append(param, a.DbName(), table)
-		case PostgreSql:
-			query = sqlQueryTableSchemaPostgreSql
-			param = append(param, table)
-		default:
-			return nil, errorStr(errorUnknownSqlTypeForAgentTables)
-		}
-	}
-	if result, err := a.QueryWithSql(query, param...); err != nil {
+func (a *Agent) execOps(ctx context.Context, tx *Tx, ops Operations, id string, args ...interface{}) (result Result, err error) {
+	var param map[string]interface{}
+	if param, _, err = a.checkArgs(args...); err != nil {
+		return nil, err
+	}
+	var elem *element
+	var query string
+	if elem, query, args, err = a.xmlAndParamsToQueryAndArgs(ops, id, param); err != nil {
+		return nil, err
+	}
+	ctx, cancel := elem.withTimeout(ctx)
+	defer cancel()
+	return a.exec(ctx, tx, query, args...)
+}
+
+func (a *Agent) execPrepareOps(ctx context.Context, tx *Tx, ops Operations, id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
+	var elem *element
+	var query string
+	if elem, query, args, err = a.prepareQueryAndArgs(ops, id, param, args); err != nil {
+		return nil, err
+	}
+	ctx, cancel := elem.withTimeout(ctx)
+	defer cancel()
+	return a.execPrepare(ctx, tx, query, args...)
+}
+
+func (a *Agent) xmlAndParamsToQueryAndArgs(ops Operations, id string, param map[string]interface{}) (elem *element, query string, args []interface{}, err error) {
+	if elem, err = getElement(ops, id); err != nil {
+		return nil, "", nil, err
+	}
+	if query, _, err = a.getSql(elem, param, false); err != nil {
+		return nil, "", nil, err
+	}
+	if query, args, err = a.getQueryAndArgs(query, param); err != nil {
+		return nil, "", nil, err
+	}
+	return elem, trim(query), args, nil
+}
+
+// prepareQueryAndArgs returns the prepared query of a copy of param and the args of each execution
+// the values bound by <foreach> item are inserted at their placeholders, the other placeholders take the args in order
+func (a *Agent) prepareQueryAndArgs(ops Operations, id string, param map[string]interface{}, args [][]interface{}) (elem *element, query string, list [][]interface{}, err error) {
+	if elem, err = getElement(ops, id); err != nil {
+		return nil, "", nil, err
+	}
+	var cp map[string]interface{}
+	if param != nil {
+		cp = make(map[string]interface{}, len(param))
+		for k, v := range param {
+			cp[k] = v
+		}
+	}
+	var sorQuery string
+	if sorQuery, _, err = a.getSql(elem, cp, false); err != nil {
+		return nil, "", nil, err
+	}
+	if query, _, err = a.getQueryAndArgs(sorQuery, cp); err != nil {
+		return nil, "", nil, err
+	}
+	matches := paramPattern.FindAllStringSubmatch(sorQuery, -1)
+	bound := make([]interface{}, len(matches))
+	isBound := false
+	for i, m := range matches {
+		if strings.HasPrefix(m[1], bindPrefix) {
+			if bound[i], err = propertyValue(cp[m[1]], m[2]); err != nil {
+				return nil, "", nil, err
+			}
+			isBound = true
+		}
+	}
+	if !isBound {
+		return elem, trim(query), args, nil
+	}
+	list = make([][]interface{}, len(args))
+	for i, arg := range args {
+		list[i] = make([]interface{}, 0, len(matches)+len(arg))
+		n := 0
+		for j, m := range matches {
+			if strings.HasPrefix(m[1], bindPrefix) {
+				list[i] = append(list[i], bound[j])
+			} else if n < len(arg) {
+				list[i] = append(list[i], arg[n])
+				n++
+			}
+		}
+		list[i] = append(list[i], arg[n:]...)
+	}
+	return elem, trim(query), list, nil
+}
+
+// getSql returns the query and order by of elem, !{key} is replaced by the quoted identifier of db Type
+func (a *Agent) getSql(elem *element, param map[string]interface{}, page bool) (query string, order string, err error) {
+	if query, order, err = elem.getSql(param, page); err != nil {
+		return "", "", err
+	}
+	if query, err = elem.replaceIdentifiers(a.t, query, param); err != nil {
+		return "", "", err
+	}
+	if order, err = elem.replaceIdentifiers(a.t, order, param); err != nil {
+		return "", "", err
+	}
+	return query, order, nil
+}
+
+// getQueryAndArgs replaces @{key} by the placeholder of db Type and returns the args of the placeholders
+// the key can be a property path, e.g. @{user.address.city} or @{items[0].id}
+func (a *Agent) getQueryAndArgs(sorQuery string, params map[string]interface{}) (query string, args []interface{}, err error) {
+	query = ""
+	args = make([]interface{}, 0)
+	queryIdx := 0
+	for i, v := range paramPattern.FindAllStringSubmatchIndex(sorQuery, -1) {
+		k := sorQuery[v[2]:v[3]]
+		query = fmt.Sprint(query, sorQuery[queryIdx:v[0]], a.t.Param(i))
+		queryIdx = v[1]
+		if params != nil {
+			var val interface{}
+			if val, err = propertyValue(params[k], sorQuery[v[4]:v[5]]); err != nil {
+				return "", nil, err
+			}
+			args = append(args, val)
+		}
+	}
+	query = fmt.Sprint(query, sorQuery[queryIdx:])
+	return query, args, nil
+}
+
+// namedQueryAndArgs replaces @{name} of the raw query by the placeholder of db Type and returns the args of the placeholders
+// the name can be a property path of the only map or struct arg, and returns error if it is not found
+// the query and args are returned as is if the args are not one map or struct, @{name} in the quoted strings is not replaced
+func (a *Agent) namedQueryAndArgs(sorQuery string, args []interface{}) (query string, namedArgs []interface{}, err error) {
+	if len(args) != 1 || !isNamedArg(args[0]) {
+		return sorQuery, args, nil
+	}
+	matches := unquotedMatches(paramPattern, sorQuery)
+	if len(matches) <= 0 {
+		return sorQuery, args, nil
+	}
+	query = ""
+	namedArgs = make([]interface{}, 0, len(matches))
+	queryIdx := 0
+	for i, v := range matches {
+		query = fmt.Sprint(query, sorQuery[queryIdx:v[0]], a.t.Param(i))
+		queryIdx = v[1]
+		var val interface{}
+		if val, err = namedValue(args[0], fmt.Sprint(".", sorQuery[v[2]:v[5]])); err != nil {
+			return "", nil, err
+		}
+		namedArgs = append(namedArgs, val)
+	}
+	query = fmt.Sprint(query, sorQuery[queryIdx:])
+	return query, namedArgs, nil
+}
+
+// isNamedArg reports whether arg is a map, struct or pointer to them
+func isNamedArg(arg interface{}) bool {
+	rv := reflect.ValueOf(arg)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv.Kind() == reflect.Map || rv.Kind() == reflect.Struct
+}
+
+// queryRawSql executes the raw query of the WithSql methods, @{name} is replaced by namedQueryAndArgs
+func (a *Agent) queryRawSql(ctx context.Context, tx *Tx, single bool, query string, args ...interface{}) (Result, error) {
+	if query, args, err := a.namedQueryAndArgs(query, args); err != nil {
+		return nil, err
+	} else {
+		return a.query(ctx, tx, single, query, args...)
+	}
+}
+
+// queryIterRawSql returns Rows of the raw query of the WithSql methods, @{name} is replaced by namedQueryAndArgs
+func (a *Agent) queryIterRawSql(ctx context.Context, tx *Tx, query string, args ...interface{}) (*Rows, error) {
+	if query, args, err := a.namedQueryAndArgs(query, args); err != nil {
+		return nil, err
+	} else {
+		return a.queryIter(ctx, nil, tx, query, args...)
+	}
+}
+
+// queryPageRawSql queries the rows from start to end of the raw query of the WithSql methods, @{name} is replaced by namedQueryAndArgs
+func (a *Agent) queryPageRawSql(ctx context.Context, tx *Tx, query, order string, start, end int64, args ...interface{}) (Result, error) {
+	if query, args, err := a.namedQueryAndArgs(query, args); err != nil {
+		return nil, err
+	} else {
+		return a.queryPageSql(ctx, tx, query, order, start, end, args...)
+	}
+}
+
+// queryPagingRawSql queries the page p of the raw query of the WithSql methods, @{name} is replaced by namedQueryAndArgs
+func (a *Agent) queryPagingRawSql(ctx context.Context, tx *Tx, query, order string, p Paging, args ...interface{}) (Result, error) {
+	if query, args, err := a.namedQueryAndArgs(query, args); err != nil {
 		return nil, err
 	} else {
-		list := make([]TableSchema, len(result.Rows()))
-		for i, v := range result.Rows() {
-			if err = jfile.Convert(v, &list[i]); err != nil {
-				return nil, err
-			}
-		}
-		return list, nil
+		return a.queryPagingWithSql(ctx, tx, query, order, "", nil, p, args...)
 	}
 }
 
-// TableSchemaTx return table schema
-// or you can use args input query statement
-func (a *Agent) TableSchemaTx(table string, args ...interface{}) ([]TableSchema, error) {
-	query := ""
-	param := make([]interface{}, 0)
-	if len(args) > 0 {
-		query = jcast.String(args[0])
-		param = args[1:]
+// queryKeysetRawSql queries the keyset page k of the raw query of the WithSql methods, @{name} is replaced by namedQueryAndArgs
+func (a *Agent) queryKeysetRawSql(ctx context.Context, tx *Tx, query string, k Keyset, args ...interface{}) (Result, error) {
+	if query, args, err := a.namedQueryAndArgs(query, args); err != nil {
+		return nil, err
 	} else {
-		switch a.t {
-		case MySql:
-			query = sqlQueryTableSchemaMySql
-			param = append(param, a.DbName(), table)
-		case MSSql:
-			query = sqlQueryTableSchemaMSSql
-			param = append(param, table)
-		case Oracle:
-			query = sqlQueryTableSchemaOracle
-			param = append(param, a.DbName(), table)
-		case PostgreSql:
-			query = sqlQueryTableSchemaPostgreSql
-			param = append(param, table)
-		default:
-			return nil, errorStr(errorUnknownSqlTypeForAgentTables)
-		}
+		return a.queryKeysetWithSql(ctx, tx, query, k, args...)
 	}
-	if result, err := a.QueryTxWithSql(query, param...); err != nil {
+}
+
+// execRawSql executes the raw query of the WithSql methods, @{name} is replaced by namedQueryAndArgs
+func (a *Agent) execRawSql(ctx context.Context, tx *Tx, query string, args ...interface{}) (Result, error) {
+	if query, args, err := a.namedQueryAndArgs(query, args); err != nil {
 		return nil, err
 	} else {
-		list := make([]TableSchema, len(result.Rows()))
-		for i, v := range result.Rows() {
-			if err = jfile.Convert(v, &list[i]); err != nil {
-				return nil, err
-			}
-		}
-		return list, nil
+		return a.exec(ctx, tx, query, args...)
 	}
 }
 
-func (a *Agent) checkArgs(args ...interface{}) (map[string]interface{}, interface{}, error) {
-	var pm map[string]interface{}
-	var v interface{}
-	for _, arg := range args {
-		switch reflect.TypeOf(arg).Kind() {
-		case reflect.Map:
-			if m, err := jcast.StringMapInterface(arg); err != nil {
-				return nil, nil, err
-			} else {
-				pm = m
-			}
-		case reflect.Struct:
-			var err error
-			var b []byte
-			if b, err = json.Marshal(&arg); err != nil {
-				return nil, nil, err
-			}
-			pm = make(map[string]interface{})
-			if err = jfile.Decode(jfile.Json.String(), b, pm); err != nil {
-				return nil, nil, err
+// queryPageSql queries the rows from start to end of query, the count query is generated from query
+func (a *Agent) queryPageSql(ctx context.Context, tx *Tx, query, order string, start, end int64, args ...interface{}) (Result, error) {
+	pageQuery, pageArgs, countQuery := getPageSql(a.t, query, order, start, end, len(args))
+	return a.queryPageWithSql(ctx, tx, pageQuery, joinArgs(args, pageArgs), countQuery, args, start, end)
+}
+
+// timeArgs returns args whose time.Time values are converted to the Location and truncated to the TimePrecision of the DataSource
+func (a *Agent) timeArgs(args []interface{}) []interface{} {
+	if a.loc == nil && a.timeUnit <= 0 {
+		return args
+	}
+	var res []interface{}
+	for i, arg := range args {
+		var t time.Time
+		switch v := arg.(type) {
+		case time.Time:
+			t = v
+		case *time.Time:
+			if v == nil {
+				continue
 			}
-		case reflect.Ptr:
-			v = arg
+			t = *v
+		default:
+			continue
 		}
+		if res == nil {
+			res = make([]interface{}, len(args))
+			copy(res, args)
+		}
+		res[i] = a.timeValue(t)
 	}
-	return pm, v, nil
+	if res == nil {
+		return args
+	}
+	return res
 }
 
-func (a *Agent) queryPrepareMS(single bool, id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	var query string
-	pm := make([]map[string]interface{}, 1)
-	pm[0] = param
-	if query, _, err = a.xmlAndParamsToQueryAndArgs(Select, id, param); err != nil {
-		return nil, err
+// timeValue returns t in the Location and truncated to the TimePrecision of the DataSource
+func (a *Agent) timeValue(t time.Time) time.Time {
+	if a.loc != nil {
+		t = t.In(a.loc)
+	}
+	if a.timeUnit > 0 {
+		t = t.Truncate(a.timeUnit)
 	}
-	return a.queryPrepare(single, query, args...)
+	return t
 }
 
-func (a *Agent) queryPrepareTxMS(single bool, id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	var query string
-	if query, _, err = a.xmlAndParamsToQueryAndArgs(Select, id, param); err != nil {
+func (a *Agent) query(ctx context.Context, tx *Tx, single bool, query string, args ...interface{}) (result Result, err error) {
+	var e executor
+	if e, err = a.executor(tx); err != nil {
+		return nil, err
+	}
+	var rows *sql.Rows
+	subject.Next(query)
+	if rows, err = e.QueryContext(ctx, query, a.timeArgs(args)...); err != nil {
 		return nil, err
 	}
-	return a.queryPrepareTx(single, query, args...)
+	return a.getResult(rows, single)
 }
 
-func (a *Agent) execOps(ops Operations, id string, args ...interface{}) (result Result, err error) {
-	var param map[string]interface{}
-	if param, _, err = a.checkArgs(args...); err != nil {
+// queryIter returns Rows of the query, the cancel is called when the Rows is closed
+func (a *Agent) queryIter(ctx context.Context, cancel context.CancelFunc, tx *Tx, query string, args ...interface{}) (*Rows, error) {
+	e, err := a.executor(tx)
+	if err != nil {
+		if cancel != nil {
+			cancel()
+		}
 		return nil, err
 	}
-	var query string
-	if query, args, err = a.xmlAndParamsToQueryAndArgs(ops, id, param); err != nil {
+	var rows *sql.Rows
+	subject.Next(query)
+	if rows, err = e.QueryContext(ctx, query, a.timeArgs(args)...); err != nil {
+		if cancel != nil {
+			cancel()
+		}
 		return nil, err
 	}
-	return a.exec(query, args...)
+	return newRows(a, rows, cancel)
 }
 
-func (a *Agent) execTxOps(ops Operations, id string, args ...interface{}) (result Result, err error) {
+func (a *Agent) queryPage(ctx context.Context, tx *Tx, id string, start, end int64, args ...interface{}) (result Result, err error) {
 	var param map[string]interface{}
-	if param, _, err = a.checkArgs(args...); err != nil {
+	var v interface{}
+	if param, v, err = a.checkArgs(args...); err != nil {
 		return nil, err
 	}
-	var query string
-	if query, args, err = a.xmlAndParamsToQueryAndArgs(ops, id, param); err != nil {
+	var elem *element
+	if elem, err = getElement(Select, id); err != nil {
 		return nil, err
 	}
-	return a.execTx(query, args...)
-}
-
-func (a *Agent) execPrepareOps(ops Operations, id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
 	var query string
-	if query, _, err = a.xmlAndParamsToQueryAndArgs(ops, id, param); err != nil {
+	var order string
+	if query, order, err = a.getSql(elem, param, true); err != nil {
 		return nil, err
 	}
-	return a.execPrepare(query, args...)
-}
-
-func (a *Agent) execPrepareTxOps(ops Operations, id string, param map[string]interface{}, args ...[]interface{}) (result []Result, err error) {
-	var query string
-	if query, _, err = a.xmlAndParamsToQueryAndArgs(ops, id, param); err != nil {
+	ctx, cancel := elem.withTimeout(ctx)
+	defer cancel()
+	var countQuery string
+	var countArgs []interface{}
+	if countQuery, countArgs, err = a.getCountQueryAndArgs(elem, param); err != nil {
 		return nil, err
 	}
-	return a.execPrepareTx(query, args...)
-}
-
-func (a *Agent) getXmlAndParam(ops Operations, id string, param []map[string]interface{}) (elem *element, pm map[string]interface{}, err error) {
-	if elem, err = getElement(ops, id); err != nil {
-		return nil, nil, err
+	if query, args, err = a.getQueryAndArgs(query, param); err != nil {
+		return nil, err
 	}
-	if len(param) > 0 {
-		pm = param[0]
-	} else {
-		pm = nil
+	pageQuery, pageArgs, genCountQuery := getPageSql(a.t, query, order, start, end, len(args))
+	if countQuery == "" {
+		countQuery, countArgs = genCountQuery, args
+	}
+	if result, err = a.queryPageWithSql(ctx, tx, pageQuery, joinArgs(args, pageArgs), countQuery, countArgs, start, end); err != nil {
+		return result, err
 	}
-	return elem, pm, nil
+	if v != nil {
+		if err = mapRows(result, true, v); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
 }
 
-func (a *Agent) xmlAndParamsToQueryAndArgs(ops Operations, id string, param map[string]interface{}) (query string, args []interface{}, err error) {
-	var elem *element
-	if elem, err = getElement(ops, id); err != nil {
+// getCountQueryAndArgs returns the query and args of the <count> tag of elem, the query is empty if there is no <count> tag
+func (a *Agent) getCountQueryAndArgs(elem *element, param map[string]interface{}) (string, []interface{}, error) {
+	query, err := elem.getCountSql(param)
+	if err != nil || query == "" {
 		return "", nil, err
 	}
-	if query, _, err = elem.getSql(param, false); err != nil {
+	if query, err = elem.replaceIdentifiers(a.t, query, param); err != nil {
 		return "", nil, err
 	}
-	query, args = a.getQueryAndArgs(query, param)
-	return trim(query), args, nil
+	return a.getQueryAndArgs(query, param)
 }
 
-func (a *Agent) getQueryAndArgs(sorQuery string, params map[string]interface{}) (query string, args []interface{}) {
-	query = ""
-	args = make([]interface{}, 0)
-	pattern := regexp.MustCompile(`@\{\w+\}`)
-	queryIdx := 0
-	for i, v := range pattern.FindAllStringSubmatchIndex(sorQuery, -1) {
-		st := "@{"
-		et := "}"
-		k := sorQuery[v[0]+len(st) : v[1]-len(et)]
-		query = fmt.Sprint(query, sorQuery[queryIdx:v[0]], a.t.Param(i))
-		queryIdx = v[1]
-		if params != nil {
-			args = append(args, params[k])
-		}
+func (a *Agent) queryKeyset(ctx context.Context, tx *Tx, id string, k Keyset, args ...interface{}) (result Result, err error) {
+	var param map[string]interface{}
+	var v interface{}
+	if param, v, err = a.checkArgs(args...); err != nil {
+		return nil, err
 	}
-	query = fmt.Sprint(query, sorQuery[queryIdx:])
-	return query, args
-}
-
-func (a *Agent) query(single bool, query string, args ...interface{}) (result Result, err error) {
-	if a.db == nil {
-		return nil, errorStr(errorDBNil)
+	var elem *element
+	if elem, err = getElement(Select, id); err != nil {
+		return nil, err
 	}
-	var rows *sql.Rows
-	subject.Next(query)
-	if rows, err = a.db.Query(query, args...); err != nil {
+	var query string
+	if query, _, err = a.getSql(elem, param, true); err != nil {
 		return nil, err
 	}
-	return a.getResult(rows, single)
+	ctx, cancel := elem.withTimeout(ctx)
+	defer cancel()
+	if query, args, err = a.getQueryAndArgs(query, param); err != nil {
+		return nil, err
+	}
+	if result, err = a.queryKeysetWithSql(ctx, tx, query, k, args...); err != nil {
+		return result, err
+	}
+	if v != nil {
+		if err = mapRows(result, false, v); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
 }
 
-func (a *Agent) queryTx(single bool, query string, args ...interface{}) (result Result, err error) {
-	if a.tx == nil {
-		return nil, errorStr(errorDbNotBegin)
+func (a *Agent) queryKeysetWithSql(ctx context.Context, tx *Tx, query string, k Keyset, args ...interface{}) (result Result, err error) {
+	if query, args, err = getKeysetSql(a.t, query, args, k); err != nil {
+		return nil, err
 	}
-	var rows *sql.Rows
-	subject.Next(query)
-	if rows, err = a.tx.Query(query, args...); err != nil {
+	if result, err = a.query(ctx, tx, false, query, args...); err != nil {
 		return nil, err
 	}
-	return a.getResult(rows, single)
+	return a.deletePagingId(result), nil
 }
 
-func (a *Agent) queryPage(ct bool, id string, start, end int64, args ...interface{}) (result Result, err error) {
+func (a *Agent) queryPaging(ctx context.Context, tx *Tx, id string, p Paging, args ...interface{}) (result Result, err error) {
 	var param map[string]interface{}
 	var v interface{}
 	if param, v, err = a.checkArgs(args...); err != nil {
@@ -814,128 +1654,141 @@ func (a *Agent) queryPage(ct bool, id string, start, end int64, args ...interfac
 	}
 	var query string
 	var order string
-	if query, order, err = elem.getSql(param, true); err != nil {
+	if query, order, err = a.getSql(elem, param, true); err != nil {
+		return nil, err
+	}
+	ctx, cancel := elem.withTimeout(ctx)
+	defer cancel()
+	var countQuery string
+	var countArgs []interface{}
+	if countQuery, countArgs, err = a.getCountQueryAndArgs(elem, param); err != nil {
+		return nil, err
+	}
+	if query, args, err = a.getQueryAndArgs(query, param); err != nil {
 		return nil, err
 	}
-	pageQuery, countQuery := getPageSql(a.t, query, order, start, end)
-	pageQuery, args = a.getQueryAndArgs(pageQuery, param)
-	countQuery, _ = a.getQueryAndArgs(countQuery, param)
-	if result, err = a.queryPageWithSql(ct, pageQuery, countQuery, start, end, args...); err != nil {
+	if result, err = a.queryPagingWithSql(ctx, tx, query, order, countQuery, countArgs, p, args...); err != nil {
 		return result, err
 	}
 	if v != nil {
-		m := map[string]interface{}{
-			"Rows":        result.Rows(),
-			"RowStart":    result.RowStart(),
-			"RowEnd":      result.RowEnd(),
-			"TotalRecord": result.TotalRecord(),
-		}
-		if err = jfile.Convert(m, v); err != nil {
+		if err = mapRows(result, true, v); err != nil {
 			return result, err
 		}
 	}
 	return result, nil
 }
 
-func (a *Agent) queryPageWithSql(ct bool, pageQuery, countQuery string, start, end int64, args ...interface{}) (result Result, err error) {
-	if ct {
-		if _, err = a.Begin(); err != nil {
+// queryPagingWithSql queries the page p of query, the count query is generated from query if countQuery is empty
+func (a *Agent) queryPagingWithSql(ctx context.Context, tx *Tx, query, order, countQuery string, countArgs []interface{}, p Paging, args ...interface{}) (Result, error) {
+	start, end, err := p.rows()
+	if err != nil {
+		return nil, err
+	}
+	info := PageInfo{Page: p.Page, PageSize: p.PageSize, HasPrev: p.Page > 1}
+	if p.SkipCount {
+		pageQuery, pageArgs, _ := getPageSql(a.t, query, order, start, end+1, len(args))
+		var res Result
+		if res, err = a.query(ctx, tx, false, pageQuery, joinArgs(args, pageArgs)...); err != nil {
+			return nil, err
+		}
+		r := a.deletePagingId(res).(agentResult)
+		if int64(len(r.rows)) > p.PageSize {
+			r.rows = r.rows[:p.PageSize]
+			r.values = r.values[:p.PageSize]
+			info.HasNext = true
+		}
+		info.TotalRecord = -1
+		info.TotalPages = -1
+		r.rowStart = start
+		r.rowEnd = end
+		r.totalRecord = -1
+		r.pageInfo = info
+		return r, nil
+	}
+	pageQuery, pageArgs, genCountQuery := getPageSql(a.t, query, order, start, end, len(args))
+	if countQuery == "" {
+		countQuery, countArgs = genCountQuery, args
+	}
+	var res Result
+	if res, err = a.queryPageWithSql(ctx, tx, pageQuery, joinArgs(args, pageArgs), countQuery, countArgs, start, end); err != nil {
+		return nil, err
+	}
+	r := res.(agentResult)
+	info.TotalRecord = r.totalRecord
+	info.TotalPages = (r.totalRecord + p.PageSize - 1) / p.PageSize
+	info.HasNext = p.Page < info.TotalPages
+	r.pageInfo = info
+	return r, nil
+}
+
+// deletePagingId returns the result without the row number column which is added by the page query
+func (a *Agent) deletePagingId(result Result) Result {
+	if res, ok := result.(agentResult); ok {
+		return res.withoutColumn(allowPagingId)
+	}
+	return result
+}
+
+// queryPageWithSql runs the page query and the count query in tx,
+// if tx is nil, they run in a new transaction which does not touch the Agent transaction
+func (a *Agent) queryPageWithSql(ctx context.Context, tx *Tx, pageQuery string, pageArgs []interface{}, countQuery string, countArgs []interface{}, start, end int64) (result Result, err error) {
+	if tx == nil {
+		if tx, err = a.BeginTxContext(ctx, nil); err != nil {
 			return nil, err
 		}
 		defer func() {
 			if err != nil {
-				if e := a.Rollback(); e != nil {
+				if e := tx.Rollback(); e != nil {
 					err = e
 				}
 			}
 		}()
-	}
-	var resPage Result
-	var resCount Result
-	if resPage, err = a.queryTx(false, pageQuery, args...); err != nil {
-		return nil, err
-	}
-	if res, ok := resPage.(agentResult); ok {
-		api := allowPagingId
-		obi := orderById
-		if a.t == PostgreSql {
-			api = strings.ToLower(api)
-			obi = strings.ToLower(obi)
-		}
-		for i := range res.rows {
-			delete(res.rows[i], api)
-			delete(res.rows[i], obi)
+		if result, err = a.queryPageWithSql(ctx, tx, pageQuery, pageArgs, countQuery, countArgs, start, end); err != nil {
+			return nil, err
 		}
-	}
-	if resCount, err = a.queryTx(true, countQuery, args...); err != nil {
-		return nil, err
-	}
-	if ct {
-		if err = a.Commit(); err != nil {
+		if err = tx.Commit(); err != nil {
 			return nil, err
 		}
+		return result, nil
 	}
-	if len(resCount.Rows()) <= 0 {
-		return nil, errorStr(errorNoRowsAvailable)
-	}
-	var m map[string]interface{}
-	m = resCount.Rows()[0]
-	var total int64
-	tr := totalRecord
-	if a.t == PostgreSql {
-		tr = strings.ToLower(tr)
+	var resPage Result
+	if resPage, err = a.query(ctx, tx, false, pageQuery, pageArgs...); err != nil {
+		return nil, err
 	}
-	if total, err = jcast.Int64(m[tr]); err != nil {
+	r := a.deletePagingId(resPage).(agentResult)
+	if err = a.queryRowScan(ctx, tx, countQuery, &r.totalRecord, countArgs...); err != nil {
 		return nil, err
 	}
-	return agentResult{
-		rows:         resPage.Rows(),
-		rowStart:     start,
-		rowEnd:       end,
-		totalRecord:  total,
-		lastInsertId: lastInsertId{id: -1, err: nil},
-		rowsAffected: rowsAffected{rows: 0, err: nil}}, nil
+	r.rowStart = start
+	r.rowEnd = end
+	return r, nil
 }
 
-func (a *Agent) queryRowScan(query string, data interface{}, args ...interface{}) error {
-	subject.Next(query)
-	return a.db.QueryRow(query, args...).Scan(data)
-}
-
-func (a *Agent) queryRowScanTx(query string, data interface{}, args ...interface{}) error {
-	subject.Next(query)
-	return a.tx.QueryRow(query, args...).Scan(data)
+func (a *Agent) queryRowScan(ctx context.Context, tx *Tx, query string, data interface{}, args ...interface{}) error {
+	if e, err := a.executor(tx); err != nil {
+		return err
+	} else {
+		subject.Next(query)
+		return e.QueryRowContext(ctx, query, a.timeArgs(args)...).Scan(data)
+	}
 }
 
-func (a *Agent) exec(query string, args ...interface{}) (result Result, err error) {
-	if a.db == nil {
-		return nil, errorStr(errorDBNil)
-	}
-	var res sql.Result
-	subject.Next(query)
-	if res, err = a.db.Exec(query, args...); err != nil {
-		return nil, err
+func (a *Agent) queryRowScanTx(ctx context.Context, query string, data interface{}, args ...interface{}) error {
+	if tx, err := a.currentTx(); err != nil {
+		return err
+	} else {
+		return a.queryRowScan(ctx, tx, query, data, args...)
 	}
-	id := lastInsertId{id: -1, err: nil}
-	ra := rowsAffected{rows: 0, err: nil}
-	id.id, id.err = res.LastInsertId()
-	ra.rows, ra.err = res.RowsAffected()
-	return agentResult{
-		rows:         nil,
-		rowStart:     0,
-		rowEnd:       0,
-		totalRecord:  0,
-		lastInsertId: id,
-		rowsAffected: ra}, nil
 }
 
-func (a *Agent) execTx(query string, args ...interface{}) (result Result, err error) {
-	if a.tx == nil {
-		return nil, errorStr(errorDbNotBegin)
+func (a *Agent) exec(ctx context.Context, tx *Tx, query string, args ...interface{}) (result Result, err error) {
+	var e executor
+	if e, err = a.executor(tx); err != nil {
+		return nil, err
 	}
 	var res sql.Result
 	subject.Next(query)
-	if res, err = a.tx.Exec(query, args...); err != nil {
+	if res, err = e.ExecContext(ctx, query, a.timeArgs(args)...); err != nil {
 		return nil, err
 	}
 	id := lastInsertId{id: -1, err: nil}
@@ -951,31 +1804,28 @@ func (a *Agent) execTx(query string, args ...interface{}) (result Result, err er
 		rowsAffected: ra}, nil
 }
 
-func (a *Agent) queryPrepare(single bool, query string, args ...[]interface{}) (result []Result, err error) {
-	if a.db == nil {
-		return nil, errorStr(errorDBNil)
-	}
-	var stmt *sql.Stmt
-	subject.Next(query)
-	if stmt, err = a.db.Prepare(query); err != nil {
+func (a *Agent) execTx(ctx context.Context, query string, args ...interface{}) (Result, error) {
+	if tx, err := a.currentTx(); err != nil {
 		return nil, err
+	} else {
+		return a.exec(ctx, tx, query, args...)
 	}
-	return a.stmtQuery(single, stmt, args...)
 }
 
-func (a *Agent) queryPrepareTx(single bool, query string, args ...[]interface{}) (result []Result, err error) {
-	if a.tx == nil {
-		return nil, errorStr(errorDbNotBegin)
+func (a *Agent) queryPrepare(ctx context.Context, tx *Tx, single bool, query string, args ...[]interface{}) (result []Result, err error) {
+	var e executor
+	if e, err = a.executor(tx); err != nil {
+		return nil, err
 	}
 	var stmt *sql.Stmt
 	subject.Next(query)
-	if stmt, err = a.tx.Prepare(query); err != nil {
+	if stmt, err = e.PrepareContext(ctx, query); err != nil {
 		return nil, err
 	}
-	return a.stmtQuery(single, stmt, args...)
+	return a.stmtQuery(ctx, single, stmt, args...)
 }
 
-func (a *Agent) stmtQuery(single bool, stmt *sql.Stmt, args ...[]interface{}) (result []Result, err error) {
+func (a *Agent) stmtQuery(ctx context.Context, single bool, stmt *sql.Stmt, args ...[]interface{}) (result []Result, err error) {
 	defer func() {
 		if e := stmt.Close(); e != nil {
 			err = e
@@ -984,7 +1834,7 @@ func (a *Agent) stmtQuery(single bool, stmt *sql.Stmt, args ...[]interface{}) (r
 	result = make([]Result, len(args))
 	for i, arg := range args {
 		var rows *sql.Rows
-		if rows, err = stmt.Query(arg...); err != nil {
+		if rows, err = stmt.QueryContext(ctx, a.timeArgs(arg)...); err != nil {
 			return nil, err
 		}
 		var res Result
@@ -996,31 +1846,20 @@ func (a *Agent) stmtQuery(single bool, stmt *sql.Stmt, args ...[]interface{}) (r
 	return result, nil
 }
 
-func (a *Agent) execPrepare(query string, args ...[]interface{}) (result []Result, err error) {
-	if a.db == nil {
-		return nil, errorStr(errorDBNil)
-	}
-	var stmt *sql.Stmt
-	subject.Next(query)
-	if stmt, err = a.db.Prepare(query); err != nil {
+func (a *Agent) execPrepare(ctx context.Context, tx *Tx, query string, args ...[]interface{}) (result []Result, err error) {
+	var e executor
+	if e, err = a.executor(tx); err != nil {
 		return nil, err
 	}
-	return a.stmtExec(stmt, args...)
-}
-
-func (a *Agent) execPrepareTx(query string, args ...[]interface{}) (result []Result, err error) {
-	if a.tx == nil {
-		return nil, errorStr(errorDbNotBegin)
-	}
 	var stmt *sql.Stmt
 	subject.Next(query)
-	if stmt, err = a.tx.Prepare(query); err != nil {
+	if stmt, err = e.PrepareContext(ctx, query); err != nil {
 		return nil, err
 	}
-	return a.stmtExec(stmt, args...)
+	return a.stmtExec(ctx, stmt, args...)
 }
 
-func (a *Agent) stmtExec(stmt *sql.Stmt, args ...[]interface{}) (result []Result, err error) {
+func (a *Agent) stmtExec(ctx context.Context, stmt *sql.Stmt, args ...[]interface{}) (result []Result, err error) {
 	defer func() {
 		if e := stmt.Close(); e != nil {
 			err = e
@@ -1029,7 +1868,7 @@ func (a *Agent) stmtExec(stmt *sql.Stmt, args ...[]interface{}) (result []Result
 	result = make([]Result, len(args))
 	for i, arg := range args {
 		var res sql.Result
-		if res, err = stmt.Exec(arg...); err != nil {
+		if res, err = stmt.ExecContext(ctx, a.timeArgs(arg)...); err != nil {
 			return nil, err
 		}
 		id := lastInsertId{id: -1, err: nil}
@@ -1047,9 +1886,9 @@ func (a *Agent) stmtExec(stmt *sql.Stmt, args ...[]interface{}) (result []Result
 	return result, nil
 }
 
-func (a *Agent) getSqlAndArgs(ops Operations, id string, args ...interface{}) (string, []interface{}, error) {
+func (a *Agent) getSqlAndArgs(ops Operations, id string, args ...interface{}) (*element, string, []interface{}, error) {
 	if param, _, err := a.checkArgs(args...); err != nil {
-		return "", nil, err
+		return nil, "", nil, err
 	} else {
 		return a.xmlAndParamsToQueryAndArgs(ops, id, param)
 	}
@@ -1070,27 +1909,25 @@ func (a *Agent) getResult(rows *sql.Rows, single bool) (result Result, err error
 		return nil, err
 	}
 	r := make([]map[string]interface{}, 0)
+	var values [][]interface{}
 	for rows.Next() {
-		rowValue := make([]interface{}, len(colTypes))
-		rowParam := make([]interface{}, len(colTypes))
-		for i, colType := range colTypes {
-			if colType.ScanType() == nil {
-				rowValue[i] = nil
-			} else {
-				rowValue[i] = reflect.New(colType.ScanType())
-			}
-			rowParam[i] = reflect.ValueOf(&rowValue[i]).Interface()
-		}
-		if err = rows.Scan(rowParam...); err != nil {
+		var value []interface{}
+		if value, err = a.scanValues(rows, colTypes); err != nil {
 			return nil, err
 		}
-		r = append(r, a.getRecord(colTypes, rowValue))
+		r = append(r, getRecord(colTypes, value))
+		values = append(values, value)
 		if single {
 			break
 		}
 	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
 	return agentResult{
 		rows:         r,
+		columns:      newColumns(colTypes),
+		values:       values,
 		rowStart:     0,
 		rowEnd:       0,
 		totalRecord:  int64(len(r)),
@@ -1098,199 +1935,40 @@ func (a *Agent) getResult(rows *sql.Rows, single bool) (result Result, err error
 		rowsAffected: rowsAffected{rows: 0, err: nil}}, nil
 }
 
-func (a *Agent) getRecord(colTypes []*sql.ColumnType, rowValue []interface{}) map[string]interface{} {
-	record := make(map[string]interface{})
+func (a *Agent) scanRecord(rows *sql.Rows, colTypes []*sql.ColumnType) (map[string]interface{}, error) {
+	if value, err := a.scanValues(rows, colTypes); err != nil {
+		return nil, err
+	} else {
+		return getRecord(colTypes, value), nil
+	}
+}
+
+// scanValues scans the current row and returns the converted values in column order
+func (a *Agent) scanValues(rows *sql.Rows, colTypes []*sql.ColumnType) ([]interface{}, error) {
+	rowValue := make([]interface{}, len(colTypes))
+	rowParam := make([]interface{}, len(colTypes))
 	for i, colType := range colTypes {
-		if rowValue[i] != nil {
-			if colType.ScanType() == nil {
-				record[colType.Name()] = rowValue[i]
-			} else {
-				dbType := colType.DatabaseTypeName()
-				st := colType.ScanType().String()
-				switch st {
-				case "time.Time":
-					fallthrough
-				case "sql.NullTime":
-					var ok bool
-					if record[colType.Name()], ok = rowValue[i].(time.Time); !ok {
-						record[colType.Name()] = rowValue[i]
-					}
-				case "int32":
-					fallthrough
-				case "sql.NullInt32":
-					switch rowValue[i].(type) {
-					case []byte:
-						var err error
-						if record[colType.Name()], err = strconv.ParseInt(string(rowValue[i].([]byte)), 10, 32); err != nil {
-							record[colType.Name()] = rowValue[i]
-						}
-					default:
-						record[colType.Name()] = rowValue[i]
-					}
-				case "int64":
-					fallthrough
-				case "sql.NullInt64":
-					switch rowValue[i].(type) {
-					case []byte:
-						var err error
-						if record[colType.Name()], err = strconv.ParseInt(string(rowValue[i].([]byte)), 10, 64); err != nil {
-							record[colType.Name()] = rowValue[i]
-						}
-					default:
-						record[colType.Name()] = rowValue[i]
-					}
-				case "string":
-					fallthrough
-				case "sql.NullString":
-					switch dbType {
-					case "DOUBLE":
-						fallthrough
-					case "DECIMAL":
-						fallthrough
-					case "NUMERIC":
-						if v, ok := rowValue[i].([]byte); ok {
-							var err error
-							if record[colType.Name()], err = strconv.ParseFloat(string(v), 64); err != nil {
-								record[colType.Name()] = rowValue[i]
-							}
-						} else {
-							record[colType.Name()] = rowValue[i]
-						}
-					default:
-						if v, ok := rowValue[i].([]byte); ok {
-							record[colType.Name()] = string(v)
-						} else {
-							record[colType.Name()] = rowValue[i]
-						}
-					}
-				case "sql.RawBytes":
-					switch dbType {
-					case "DOUBLE":
-						var err error
-						if record[colType.Name()], err = strconv.ParseFloat(string(rowValue[i].([]byte)), 64); err != nil {
-							record[colType.Name()] = rowValue[i]
-						}
-					case "DECIMAL":
-						var err error
-						if record[colType.Name()], err = strconv.ParseFloat(string(rowValue[i].([]byte)), 64); err != nil {
-							record[colType.Name()] = rowValue[i]
-						}
-					case "NUMERIC":
-						var err error
-						if record[colType.Name()], err = strconv.ParseFloat(string(rowValue[i].([]byte)), 64); err != nil {
-							record[colType.Name()] = rowValue[i]
-						}
-					case "BLOB":
-						var ok bool
-						if record[colType.Name()], ok = rowValue[i].([]byte); !ok {
-							record[colType.Name()] = rowValue[i]
-						}
-					case "JSON":
-						if b, ok := rowValue[i].([]byte); ok {
-							var j interface{}
-							if err := json.Unmarshal(b, &j); err != nil {
-								record[colType.Name()] = string(b)
-							} else {
-								switch j.(type) {
-								case []interface{}:
-									if record[colType.Name()], ok = j.([]interface{}); !ok {
-										record[colType.Name()] = string(b)
-									}
-								case map[string]interface{}:
-									if record[colType.Name()], ok = j.(map[string]interface{}); !ok {
-										record[colType.Name()] = string(b)
-									}
-								default:
-									record[colType.Name()] = string(b)
-								}
-							}
-						} else {
-							record[colType.Name()] = rowValue[i]
-						}
-					default:
-						if b, ok := rowValue[i].([]byte); !ok {
-							record[colType.Name()] = rowValue[i]
-						} else {
-							record[colType.Name()] = string(b)
-						}
-					}
-				case "sql.NullFloat64":
-					fallthrough
-				case "interface {}":
-					fallthrough
-				case "[]uint8":
-					switch dbType {
-					case "DOUBLE":
-						fallthrough
-					case "DECIMAL":
-						fallthrough
-					case "NUMERIC":
-						if v, ok := rowValue[i].([]uint8); ok {
-							var err error
-							if record[colType.Name()], err = strconv.ParseFloat(string(v), 64); err != nil {
-								record[colType.Name()] = rowValue[i]
-							}
-						} else {
-							record[colType.Name()] = rowValue[i]
-						}
-					case "JSON":
-						if b, ok := rowValue[i].([]byte); ok {
-							var j interface{}
-							if err := json.Unmarshal(b, &j); err != nil {
-								record[colType.Name()] = string(b)
-							} else {
-								switch j.(type) {
-								case []interface{}:
-									if record[colType.Name()], ok = j.([]interface{}); !ok {
-										record[colType.Name()] = string(b)
-									}
-								case map[string]interface{}:
-									if record[colType.Name()], ok = j.(map[string]interface{}); !ok {
-										record[colType.Name()] = string(b)
-									}
-								default:
-									record[colType.Name()] = string(b)
-								}
-							}
-						} else {
-							record[colType.Name()] = rowValue[i]
-						}
-					default:
-						if st == "interface {}" {
-							if b, ok := rowValue[i].([]byte); !ok {
-								record[colType.Name()] = rowValue[i]
-							} else {
-								record[colType.Name()] = string(b)
-							}
-						} else {
-							record[colType.Name()] = rowValue[i]
-						}
-					}
-				case "godror.Number":
-					var err error
-					if record[colType.Name()], err = strconv.ParseFloat(jcast.String(rowValue[i]), 64); err != nil {
-						record[colType.Name()] = rowValue[i]
-					}
-				default:
-					switch dbType {
-					case "DOUBLE":
-						var err error
-						if record[colType.Name()], err = strconv.ParseFloat(string(rowValue[i].([]byte)), 64); err != nil {
-							record[colType.Name()] = rowValue[i]
-						}
-					case "DECIMAL":
-						var err error
-						if record[colType.Name()], err = strconv.ParseFloat(string(rowValue[i].([]byte)), 64); err != nil {
-							record[colType.Name()] = rowValue[i]
-						}
-					default:
-						record[colType.Name()] = rowValue[i]
-					}
-				}
-			}
+		if colType.ScanType() == nil {
+			rowValue[i] = nil
 		} else {
-			record[colType.Name()] = nil
+			rowValue[i] = reflect.New(colType.ScanType())
 		}
+		rowParam[i] = reflect.ValueOf(&rowValue[i]).Interface()
+	}
+	if err := rows.Scan(rowParam...); err != nil {
+		return nil, err
+	}
+	for i, colType := range colTypes {
+		rowValue[i] = a.convertColumn(colType, rowValue[i])
+	}
+	return rowValue, nil
+}
+
+// getRecord returns the record of the values by column name, the later column of the same name overwrites the former
+func getRecord(colTypes []*sql.ColumnType, rowValue []interface{}) map[string]interface{} {
+	record := make(map[string]interface{})
+	for i, colType := range colTypes {
+		record[colType.Name()] = rowValue[i]
 	}
 	return record
 }
@@ -0,0 +1,186 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAgent_NamedQueryAndArgs(t *testing.T) {
+	type user struct {
+		ID   int    `jsql:"ID"`
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		t     Type
+		query string
+		args  []interface{}
+		out   string
+		named []interface{}
+		err   bool
+	}{
+		{MySql, "SELECT * FROM T WHERE ID = ?", []interface{}{1}, "SELECT * FROM T WHERE ID = ?", []interface{}{1}, false},
+		{MySql, "SELECT * FROM T WHERE ID = @{id} AND NAME = @{name}", []interface{}{map[string]interface{}{"id": 1, "name": "a"}},
+			"SELECT * FROM T WHERE ID = ? AND NAME = ?", []interface{}{1, "a"}, false},
+		{PostgreSql, "SELECT * FROM T WHERE ID = @{id} AND NAME = @{name}", []interface{}{user{ID: 1, Name: "a"}},
+			"SELECT * FROM T WHERE ID = $1 AND NAME = $2", []interface{}{1, "a"}, false},
+		{MSSql, "SELECT * FROM T WHERE ID = @{u.id}", []interface{}{&map[string]interface{}{"u": &user{ID: 2}}},
+			"SELECT * FROM T WHERE ID = @p1", []interface{}{2}, false},
+		{MySql, "SELECT '@{id}' FROM T WHERE ID = ?", []interface{}{1}, "SELECT '@{id}' FROM T WHERE ID = ?", []interface{}{1}, false},
+		{MySql, "SELECT '@{id}' FROM T", nil, "SELECT '@{id}' FROM T", nil, false},
+		{MySql, "SELECT * FROM T WHERE ID = @{id}", []interface{}{map[string]interface{}{}, 1},
+			"SELECT * FROM T WHERE ID = @{id}", []interface{}{map[string]interface{}{}, 1}, false},
+		{MySql, "SELECT '@{id}', \"@{id}\" FROM T WHERE ID = @{id} AND NAME = 'it''s @{name}'", []interface{}{map[string]interface{}{"id": 1}},
+			"SELECT '@{id}', \"@{id}\" FROM T WHERE ID = ? AND NAME = 'it''s @{name}'", []interface{}{1}, false},
+		{MySql, "SELECT * FROM T WHERE ID = @{id}", []interface{}{map[string]interface{}{"id": nil}}, "SELECT * FROM T WHERE ID = ?", []interface{}{nil}, false},
+		{MySql, "SELECT * FROM T WHERE ID = @{unknown}", []interface{}{user{}}, "", nil, true},
+		{MySql, "SELECT * FROM T WHERE ID = @{idd}", []interface{}{map[string]interface{}{"id": 1}}, "", nil, true},
+		{MySql, "SELECT * FROM T WHERE ID = @{u.id}", []interface{}{map[string]interface{}{"u": nil}}, "", nil, true},
+		{MySql, "SELECT * FROM T WHERE ID = @{ids[1]}", []interface{}{map[string]interface{}{"ids": []int{1}}}, "", nil, true},
+	}
+	for _, v := range tests {
+		query, args, err := (&Agent{t: v.t}).namedQueryAndArgs(v.query, v.args)
+		assert.Equal(t, v.err, err != nil, v.query)
+		assert.Equal(t, v.out, query)
+		assert.Equal(t, v.named, args)
+	}
+}
+
+func TestAgent_QueryWithSql_Named(t *testing.T) {
+	cols := []testColumn{{name: "ID", dbType: "BIGINT", scanType: reflect.TypeOf(int64(0))}}
+	query := "SELECT ID FROM T WHERE ID > $1 AND NAME = $2"
+	testSetQuery(query, cols, []driver.Value{int64(3)})
+	a := &Agent{db: testOpenDB(), t: PostgreSql}
+	res, err := a.QueryWithSql("SELECT ID FROM T WHERE ID > @{id} AND NAME = @{name}", map[string]interface{}{"id": int64(2), "name": "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]interface{}{{"ID": int64(3)}}, res.Rows())
+	assert.Equal(t, []driver.Value{int64(2), "a"}, testTakeArgs(query))
+	_, err = a.ExecWithSql("DELETE FROM T WHERE ID = @{id}", struct{ ID int64 }{ID: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"DELETE FROM T WHERE ID = $1"}, testTakeExecs())
+}
+
+func TestAgent_TimeArgs(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*60*60)
+	tm := time.Date(2022, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	a := &Agent{loc: loc, timeUnit: time.Millisecond}
+	args := a.timeArgs([]interface{}{1, tm, &tm, (*time.Time)(nil)})
+	want := time.Date(2022, 1, 2, 11, 4, 5, 123000000, loc)
+	assert.Equal(t, []interface{}{1, want, want, (*time.Time)(nil)}, args)
+	assert.True(t, tm.Equal(args[1].(time.Time).Add(456789)))
+	args = []interface{}{tm}
+	assert.Equal(t, args, (&Agent{}).timeArgs(args))
+}
+
+func TestDataSource_TimeOptions(t *testing.T) {
+	tests := []struct {
+		loc       string
+		precision int
+		unit      time.Duration
+		err       bool
+	}{
+		{"", -1, 0, false},
+		{"UTC", 0, time.Second, false},
+		{"Local", 3, time.Millisecond, false},
+		{"", 6, time.Microsecond, false},
+		{"", 9, 0, false},
+		{"Unknown/Zone", 0, 0, true},
+	}
+	for _, v := range tests {
+		ds := (&dataSource{}).getDefault()
+		ds.Location, ds.TimePrecision = v.loc, v.precision
+		loc, unit, err := ds.timeOptions()
+		assert.Equal(t, v.err, err != nil)
+		assert.Equal(t, v.unit, unit)
+		if v.loc != "" && !v.err {
+			assert.Equal(t, v.loc, loc.String())
+		} else {
+			assert.Nil(t, loc)
+		}
+	}
+}
+
+func TestAgent_CheckArgs_Time(t *testing.T) {
+	tm := time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC)
+	type arg struct {
+		Created time.Time  `json:"created"`
+		Updated *time.Time `json:"updated,omitempty"`
+		Deleted *time.Time
+		Name    string
+	}
+	pm, _, err := (&Agent{}).checkArgs(arg{Created: tm, Updated: &tm, Name: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"created": tm, "updated": tm, "Deleted": nil, "Name": "a"}, pm)
+
+	type base struct {
+		Created time.Time `json:"created"`
+	}
+	type user struct {
+		base
+		Logins []time.Time            `json:"logins"`
+		Roles  map[string]*arg        `json:"roles"`
+		Parent *user                  `json:"parent"`
+		Extra  map[string]interface{} `json:"extra"`
+	}
+	pm, _, err = (&Agent{}).checkArgs(struct {
+		User user `json:"user"`
+	}{user{base: base{tm}, Logins: []time.Time{tm}, Roles: map[string]*arg{"r": {Created: tm}},
+		Parent: &user{base: base{tm}}, Extra: map[string]interface{}{"t": tm}}})
+	assert.Nil(t, err)
+	for _, path := range []string{".created", ".logins[0]", ".roles.r.created", ".parent.created", ".extra.t"} {
+		v, err := propertyValue(pm["user"], path)
+		assert.Nil(t, err)
+		assert.Equal(t, tm, v, path)
+	}
+}
+
+func TestAgent_Query_Time(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*60*60)
+	tm := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	cols := []testColumn{{name: "CREATED", dbType: "DATETIME", scanType: reflect.TypeOf(time.Time{})}}
+	query := "SELECT CREATED FROM T WHERE CREATED > ?"
+	testSetQuery(query, cols, []driver.Value{tm})
+	a := &Agent{db: testOpenDB(), t: MySql, loc: loc}
+	res, err := a.QueryWithSql("SELECT CREATED FROM T WHERE CREATED > @{created}", map[string]interface{}{"created": tm})
+	assert.Nil(t, err)
+	assert.Equal(t, []driver.Value{tm.In(loc)}, testTakeArgs(query))
+	assert.Equal(t, loc, res.Rows()[0]["CREATED"].(time.Time).Location())
+	assert.True(t, tm.Equal(res.Rows()[0]["CREATED"].(time.Time)))
+}
+
+func TestAgent_InsertPrepare_ForeachItem(t *testing.T) {
+	dao := testDao(t, `<dao>
+    <insert id="prepareItem">
+        INSERT INTO T (ID, NAME, SEQ) SELECT @{id}, @{name}, SEQ FROM S WHERE SEQ IN
+        <foreach params="seqs" item="seq" open="(" separator="," close=")">@{seq}</foreach>
+    </insert>
+</dao>`)
+	mux.Lock()
+	im := insertMap
+	insertMap = map[string]*element{"prepareItem": dao.nodes[0]}
+	mux.Unlock()
+	defer func() {
+		mux.Lock()
+		insertMap = im
+		mux.Unlock()
+	}()
+	a := &Agent{db: testOpenDB(), t: MySql}
+	testTakeExecs()
+	param := map[string]interface{}{"seqs": []int{7, 8}}
+	for i := 0; i < 2; i++ {
+		res, err := a.InsertPrepare("prepareItem", param, []interface{}{1, "a"}, []interface{}{2, "b"})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(res))
+		// the values bound by item are not written into param
+		assert.Equal(t, 1, len(param))
+	}
+	query := "INSERT INTO T (ID, NAME, SEQ) SELECT ?, ?, SEQ FROM S WHERE SEQ IN (?, ?)"
+	assert.Equal(t, []string{query, query, query, query}, testTakeExecs())
+	assert.Equal(t, []driver.Value{int64(2), "b", int64(7), int64(8)}, testTakeArgs(query))
+}
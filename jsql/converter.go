@@ -0,0 +1,222 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/xjustloveux/jgo/jcast"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ColumnConverter converts the not nil value scanned from the column to the value of Result,
+// the scanned value is returned as is if the ColumnConverter returns an error
+type ColumnConverter func(col *sql.ColumnType, v interface{}) (interface{}, error)
+
+// DecimalMode is the value type of DECIMAL, NUMERIC and NUMBER columns of Result
+type DecimalMode int
+
+const (
+	// DecimalFloat64 converts to float64, it may lose precision
+	DecimalFloat64 DecimalMode = iota
+	// DecimalString converts to string
+	DecimalString
+	// DecimalRat converts to *big.Rat
+	DecimalRat
+)
+
+var (
+	converterMux = new(sync.RWMutex)
+	converters   = make(map[string]ColumnConverter)
+	decimalFuncs = make(map[string]func(string) (interface{}, error))
+	// decimalTypes are converted by the DecimalMode of the DataSource if no ColumnConverter is registered
+	decimalTypes = map[string]bool{"DECIMAL": true, "NUMERIC": true, "NUMBER": true}
+)
+
+func init() {
+	RegisterColumnConverter("DOUBLE", convertFloat)
+	RegisterColumnConverter("JSON", convertJson)
+	RegisterColumnConverter("BLOB", convertBytes)
+}
+
+// RegisterColumnConverter registers the ColumnConverter of the DatabaseTypeName of sql.ColumnType
+// the dbType is case-insensitive, nil ColumnConverter removes the registered one
+func RegisterColumnConverter(dbType string, c ColumnConverter) {
+	converterMux.Lock()
+	defer func() {
+		converterMux.Unlock()
+	}()
+	dbType = strings.ToUpper(dbType)
+	if c == nil {
+		delete(converters, dbType)
+		return
+	}
+	converters[dbType] = c
+}
+
+// RegisterDecimalFunc registers the function that converts the string of DECIMAL, NUMERIC and NUMBER columns to the value of Result,
+// e.g. the constructor of a decimal package, the DataSource uses it by setting DecimalMode to the name
+// the name is case-insensitive, nil function removes the registered one
+func RegisterDecimalFunc(name string, f func(string) (interface{}, error)) {
+	converterMux.Lock()
+	defer func() {
+		converterMux.Unlock()
+	}()
+	name = strings.ToLower(name)
+	if f == nil {
+		delete(decimalFuncs, name)
+		return
+	}
+	decimalFuncs[name] = f
+}
+
+// ParseDecimalMode returns the DecimalMode of Float64, String or Rat, the m is case-insensitive, empty m is DecimalFloat64
+// if m is the name registered by RegisterDecimalFunc, it returns DecimalFloat64 and the function
+func ParseDecimalMode(m string) (DecimalMode, func(string) (interface{}, error), error) {
+	switch strings.ToLower(m) {
+	case "", "float64":
+		return DecimalFloat64, nil, nil
+	case "string":
+		return DecimalString, nil, nil
+	case "rat":
+		return DecimalRat, nil, nil
+	}
+	converterMux.RLock()
+	f := decimalFuncs[strings.ToLower(m)]
+	converterMux.RUnlock()
+	if f == nil {
+		return DecimalFloat64, nil, errorFmt(errorNotValidDecimalMode, m)
+	}
+	return DecimalFloat64, f, nil
+}
+
+// convertColumn returns the value of Result of the value v scanned from the column col
+func (a *Agent) convertColumn(col *sql.ColumnType, v interface{}) interface{} {
+	dbType := strings.ToUpper(col.DatabaseTypeName())
+	converterMux.RLock()
+	c := converters[dbType]
+	converterMux.RUnlock()
+	if v != nil && col.ScanType() != nil {
+		if c == nil && decimalTypes[dbType] {
+			c = a.convertDecimal
+		}
+		if c == nil {
+			c = convertDefault
+		}
+		if res, err := c(col, v); err == nil {
+			v = res
+		}
+	}
+	if t, ok := v.(time.Time); ok && a.loc != nil {
+		v = t.In(a.loc)
+	}
+	if a.nullWrapper {
+		v = wrapNull(col, v)
+	}
+	return v
+}
+
+// wrapNull returns the sql.Null* value of v if the scan type of col is sql.Null*, otherwise v
+func wrapNull(col *sql.ColumnType, v interface{}) interface{} {
+	st := col.ScanType()
+	if st == nil || st.Kind() != reflect.Struct || !reflect.PointerTo(st).Implements(scannerType) {
+		return v
+	}
+	if _, ok := st.FieldByName("Valid"); !ok {
+		return v
+	}
+	w := reflect.New(st)
+	if err := w.Interface().(sql.Scanner).Scan(v); err != nil {
+		return v
+	}
+	return w.Elem().Interface()
+}
+
+// convertDefault converts the []byte by the scan type of col
+func convertDefault(col *sql.ColumnType, v interface{}) (interface{}, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return v, nil
+	}
+	switch col.ScanType().String() {
+	case "int32", "sql.NullInt32":
+		return strconv.ParseInt(string(b), 10, 32)
+	case "int64", "sql.NullInt64":
+		return strconv.ParseInt(string(b), 10, 64)
+	case "string", "sql.NullString", "sql.RawBytes", "interface {}":
+		return string(b), nil
+	}
+	return v, nil
+}
+
+// convertDecimal converts the decimal by the DecimalMode or the decimal function of the DataSource
+func (a *Agent) convertDecimal(_ *sql.ColumnType, v interface{}) (interface{}, error) {
+	mode, f := a.decimalMode, a.decimalFunc
+	var s string
+	if b, ok := v.([]byte); ok {
+		s = string(b)
+	} else if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+		// e.g. godror.Number
+		s = rv.String()
+	} else if f == nil && mode == DecimalFloat64 {
+		return v, nil
+	} else {
+		s = jcast.String(v)
+	}
+	if f != nil {
+		return f(s)
+	}
+	switch mode {
+	case DecimalString:
+		return s, nil
+	case DecimalRat:
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return nil, errorFmt(errorWrongDecimal, s)
+		}
+		return r, nil
+	default:
+		return strconv.ParseFloat(s, 64)
+	}
+}
+
+// convertFloat converts the []byte or string to float64
+func convertFloat(_ *sql.ColumnType, v interface{}) (interface{}, error) {
+	if b, ok := v.([]byte); ok {
+		return strconv.ParseFloat(string(b), 64)
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+		return strconv.ParseFloat(rv.String(), 64)
+	}
+	return v, nil
+}
+
+// convertJson converts the []byte of json array or object to []interface{} or map[string]interface{}, other json to string
+func convertJson(_ *sql.ColumnType, v interface{}) (interface{}, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return v, nil
+	}
+	var j interface{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return string(b), nil
+	}
+	switch j.(type) {
+	case []interface{}, map[string]interface{}:
+		return j, nil
+	default:
+		return string(b), nil
+	}
+}
+
+// convertBytes keeps the []byte
+func convertBytes(_ *sql.ColumnType, v interface{}) (interface{}, error) {
+	return v, nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAgent_ConvertColumn_Decimal(t *testing.T) {
+	cols := []testColumn{
+		{name: "AMOUNT", dbType: "DECIMAL", scanType: reflect.TypeOf(sql.RawBytes{})},
+		{name: "NAME", dbType: "VARCHAR", scanType: reflect.TypeOf(sql.RawBytes{})},
+	}
+	query := "SELECT AMOUNT, NAME FROM T"
+	testSetQuery(query, cols, []driver.Value{[]byte("12345678901234567.89"), []byte("a")})
+	amount, _ := new(big.Rat).SetString("12345678901234567.89")
+	tests := []struct {
+		mode DecimalMode
+		f    func(string) (interface{}, error)
+		out  interface{}
+	}{
+		{DecimalFloat64, nil, 12345678901234567.89},
+		{DecimalString, nil, "12345678901234567.89"},
+		{DecimalRat, nil, amount},
+		{DecimalFloat64, func(s string) (interface{}, error) { return strings.Split(s, "."), nil }, []string{"12345678901234567", "89"}},
+	}
+	for _, v := range tests {
+		a := &Agent{db: testOpenDB(), t: MySql, decimalMode: v.mode, decimalFunc: v.f}
+		res, err := a.QueryWithSql(query)
+		assert.Nil(t, err)
+		assert.Equal(t, v.out, res.Rows()[0]["AMOUNT"])
+		assert.Equal(t, "a", res.Rows()[0]["NAME"])
+	}
+	a := &Agent{db: testOpenDB(), t: MySql, decimalMode: DecimalRat}
+	res, err := a.QueryWithSql(query)
+	assert.Nil(t, err)
+	var rows []struct {
+		Amount float64 `jsql:"AMOUNT"`
+	}
+	assert.Nil(t, mapRows(res, false, &rows))
+	assert.Equal(t, 12345678901234567.89, rows[0].Amount)
+}
+
+func TestParseDecimalMode(t *testing.T) {
+	defer RegisterDecimalFunc("split", nil)
+	RegisterDecimalFunc("Split", func(s string) (interface{}, error) { return strings.Split(s, "."), nil })
+	tests := []struct {
+		input string
+		mode  DecimalMode
+		f     bool
+		err   bool
+	}{
+		{"", DecimalFloat64, false, false},
+		{"Float64", DecimalFloat64, false, false},
+		{"string", DecimalString, false, false},
+		{"RAT", DecimalRat, false, false},
+		{"split", DecimalFloat64, true, false},
+		{"decimal", DecimalFloat64, false, true},
+	}
+	for _, v := range tests {
+		mode, f, err := ParseDecimalMode(v.input)
+		assert.Equal(t, v.mode, mode, v.input)
+		assert.Equal(t, v.f, f != nil, v.input)
+		assert.Equal(t, v.err, err != nil, v.input)
+	}
+}
+
+func TestGetAgent_DecimalMode(t *testing.T) {
+	old := dsMap
+	defer func() {
+		dsMap = old
+	}()
+	db := testOpenDB()
+	dsMap = map[string]*dataSource{
+		"rat":    {Type: "MySql", DecimalMode: "Rat", db: db},
+		"string": {Type: "MySql", DecimalMode: "String", NullWrapper: true, db: db},
+		"wrong":  {Type: "MySql", DecimalMode: "Decimal", db: db},
+	}
+	cols := []testColumn{
+		{name: "AMOUNT", dbType: "NUMERIC", scanType: reflect.TypeOf(sql.RawBytes{})},
+		{name: "NAME", dbType: "VARCHAR", scanType: reflect.TypeOf(sql.NullString{})},
+	}
+	query := "SELECT AMOUNT, NAME FROM T2"
+	testSetQuery(query, cols, []driver.Value{[]byte("1.5"), nil})
+
+	a, err := GetAgent("rat")
+	assert.Nil(t, err)
+	res, err := a.QueryWithSql(query)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"AMOUNT": big.NewRat(3, 2), "NAME": nil}, res.Row())
+
+	a, err = GetAgent("string")
+	assert.Nil(t, err)
+	res, err = a.QueryWithSql(query)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"AMOUNT": "1.5", "NAME": sql.NullString{}}, res.Row())
+
+	_, err = GetAgent("wrong")
+	assert.Equal(t, errorFmt(errorNotValidDecimalMode, "Decimal"), err)
+}
+
+func TestAgent_ConvertColumn_NullWrapper(t *testing.T) {
+	cols := []testColumn{
+		{name: "NAME", dbType: "VARCHAR", scanType: reflect.TypeOf(sql.NullString{})},
+		{name: "AGE", dbType: "INT", scanType: reflect.TypeOf(sql.NullInt64{})},
+		{name: "ID", dbType: "INT", scanType: reflect.TypeOf(int64(0))},
+	}
+	query := "SELECT NAME, AGE, ID FROM T"
+	testSetQuery(query, cols, []driver.Value{nil, []byte("10"), int64(1)})
+	a := &Agent{db: testOpenDB(), t: MySql}
+	res, err := a.QueryWithSql(query)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"NAME": nil, "AGE": int64(10), "ID": int64(1)}, res.Rows()[0])
+	a.nullWrapper = true
+	res, err = a.QueryWithSql(query)
+	assert.Equal(t, map[string]interface{}{"NAME": sql.NullString{}, "AGE": sql.NullInt64{Int64: 10, Valid: true}, "ID": int64(1)},
+		res.Rows()[0])
+	var rows []struct {
+		Name *string `jsql:"NAME"`
+		Age  int     `jsql:"AGE"`
+	}
+	assert.Nil(t, mapRows(res, false, &rows))
+	assert.Nil(t, rows[0].Name)
+	assert.Equal(t, 10, rows[0].Age)
+}
+
+func TestRegisterColumnConverter(t *testing.T) {
+	defer RegisterColumnConverter("uuid", nil)
+	cols := []testColumn{{name: "ID", dbType: "UUID", scanType: reflect.TypeOf(sql.RawBytes{})}}
+	query := "SELECT ID FROM T"
+	testSetQuery(query, cols, []driver.Value{[]byte("abc")})
+	a := &Agent{db: testOpenDB(), t: PostgreSql}
+	res, err := a.QueryWithSql(query)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", res.Rows()[0]["ID"])
+	RegisterColumnConverter("uuid", func(_ *sql.ColumnType, v interface{}) (interface{}, error) {
+		return strings.ToUpper(string(v.([]byte))), nil
+	})
+	res, err = a.QueryWithSql(query)
+	assert.Nil(t, err)
+	assert.Equal(t, "ABC", res.Rows()[0]["ID"])
+}
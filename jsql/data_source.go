@@ -23,6 +23,15 @@ type dataSource struct {
 	ConnMaxIdleTimeDuration string
 	MaxOpenConns            int
 	MaxIdleConns            int
+	RetryMaxAttempts        int
+	RetryBackoff            time.Duration
+	RetryMaxBackoff         time.Duration
+	RetryBackoffDuration    string
+	RetryJitter             float64
+	Location                string
+	TimePrecision           int
+	DecimalMode             string
+	NullWrapper             bool
 	EncodeData              string
 	Format                  jfile.Format
 	db                      *sql.DB
@@ -36,6 +45,15 @@ func (*dataSource) getDefault() *dataSource {
 		ConnMaxIdleTimeDuration: "Hour",
 		MaxOpenConns:            0,
 		MaxIdleConns:            0,
+		RetryMaxAttempts:        0,
+		RetryBackoff:            0,
+		RetryMaxBackoff:         0,
+		RetryBackoffDuration:    "Millisecond",
+		RetryJitter:             0,
+		Location:                "",
+		TimePrecision:           -1,
+		DecimalMode:             "",
+		NullWrapper:             false,
 		EncodeData:              "",
 		Format:                  jfile.Json,
 	}
@@ -141,6 +159,35 @@ func (ds *dataSource) open() error {
 	return nil
 }
 
+func (ds *dataSource) retryPolicy() RetryPolicy {
+	p := RetryPolicy{MaxAttempts: ds.RetryMaxAttempts, Jitter: ds.RetryJitter}
+	if d, err := jtime.ParseTimeDuration(ds.RetryBackoffDuration); err == nil {
+		p.Backoff = ds.RetryBackoff * d
+		p.MaxBackoff = ds.RetryMaxBackoff * d
+	}
+	return p
+}
+
+// timeOptions returns the time.Location of Location, nil if Location is empty,
+// and the truncation unit of TimePrecision, 0 if TimePrecision is not between 0 and 8
+func (ds *dataSource) timeOptions() (*time.Location, time.Duration, error) {
+	var loc *time.Location
+	if ds.Location != "" {
+		var err error
+		if loc, err = time.LoadLocation(ds.Location); err != nil {
+			return nil, 0, err
+		}
+	}
+	var unit time.Duration
+	if ds.TimePrecision >= 0 && ds.TimePrecision < 9 {
+		unit = time.Second
+		for i := 0; i < ds.TimePrecision; i++ {
+			unit /= 10
+		}
+	}
+	return loc, unit, nil
+}
+
 func (ds *dataSource) close() error {
 	if ds.db == nil {
 		return errorStr(errorDbNotOpen)
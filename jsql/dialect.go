@@ -0,0 +1,107 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"strings"
+	"sync"
+)
+
+// Dialect is the sql syntax of a database
+// register a Dialect by RegisterDialect, then the DataSource.Type can use the registered name
+// a Dialect can embed a built-in Dialect, e.g. MySql.Dialect(), and override the different parts
+type Dialect interface {
+	// DriverName returns the default sql driver name
+	DriverName() string
+	// Param returns the placeholder of the i-th query parameter, the i starts from 0
+	Param(i int) string
+	// QuoteIdentifier returns the quoted identifier of name, e.g. a column or table name
+	QuoteIdentifier(name string) string
+	// PageSql returns the query and args of the rows from start to end of sql
+	// the obs is the order by columns without ORDER BY, it may be empty
+	// the sql has n args, so the placeholders of the returned args start from Param(n)
+	PageSql(sql, obs string, start, end int64, n int) (pageSql string, args []interface{})
+	// CountSql returns the query that selects the row count of sql as TOTALRECORD column
+	CountSql(sql string) string
+	// ExistsSql returns the query that selects E column 'Y' if sql has rows, otherwise 'N'
+	ExistsSql(sql string) string
+	// TablesSql returns the query and args of the table names, the column name must be TABLE_NAME
+	// empty query means not supported
+	TablesSql(dbName string) (string, []interface{})
+	// TableSchemaSql returns the query and args of the table columns, the column names are same as TableSchema json tag
+	// empty query means not supported
+	TableSchemaSql(dbName, table string) (string, []interface{})
+	// MaxParams returns the max number of the bind parameters of a statement
+	MaxParams() int
+	// MaxRows returns the max number of the rows of a batch statement, 0 means no limit
+	MaxRows() int
+	// BatchInsertSql returns the query that inserts n rows of cols into table, the placeholders start from Param(0)
+	BatchInsertSql(table string, cols []string, n int) string
+	// UpsertSql returns the query that inserts n rows of cols into table, the placeholders start from Param(0)
+	// the rows that conflict on conflictCols update updateCols, or do nothing if updateCols is empty
+	// the conflictCols may be unused, e.g. MySql conflicts on any unique key
+	// empty query means the conflictCols are needed but empty
+	UpsertSql(table string, cols, conflictCols, updateCols []string, n int) string
+	// LastInsertIdSql returns the insert query that selects the last insert id
+	LastInsertIdSql(insertSql string) string
+	// SavepointSql returns the statements to create, roll back to and release the savepoint name
+	// empty savepoint means not supported, empty release means savepoints are released at the end of the transaction
+	SavepointSql(name string) (savepoint, rollback, release string)
+	// IsRetryable reports whether err is a deadlock or serialization failure
+	IsRetryable(err error) bool
+}
+
+var (
+	dialectMux   = new(sync.RWMutex)
+	dialects     []Dialect
+	dialectNames = make(map[string]Type)
+)
+
+func init() {
+	RegisterDialect("mysql", mySqlDialect{})
+	RegisterDialect("mssql", msSqlDialect{})
+	RegisterDialect("oracle", oracleDialect{})
+	RegisterDialect("postgresql", postgreSqlDialect{})
+	RegisterDialect("sqlite", sqliteDialect{})
+}
+
+// RegisterDialect registers the Dialect by name and returns the db Type of it
+// the name is case-insensitive, registering an existing name replaces its Dialect and keeps its db Type
+func RegisterDialect(name string, d Dialect) Type {
+	dialectMux.Lock()
+	defer func() {
+		dialectMux.Unlock()
+	}()
+	name = strings.ToLower(name)
+	if t, ok := dialectNames[name]; ok {
+		dialects[t] = d
+		return t
+	}
+	t := Type(len(dialects))
+	dialects = append(dialects, d)
+	dialectNames[name] = t
+	return t
+}
+
+// Dialect returns the Dialect of db Type, or nil if the db Type is not registered
+func (t Type) Dialect() Dialect {
+	dialectMux.RLock()
+	defer func() {
+		dialectMux.RUnlock()
+	}()
+	if t < 0 || int(t) >= len(dialects) {
+		return nil
+	}
+	return dialects[t]
+}
+
+func lookupDialect(name string) (Type, bool) {
+	dialectMux.RLock()
+	defer func() {
+		dialectMux.RUnlock()
+	}()
+	t, ok := dialectNames[strings.ToLower(name)]
+	return t, ok
+}
@@ -0,0 +1,39 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testDialect struct {
+	Dialect
+}
+
+func (testDialect) DriverName() string {
+	return "mariadb"
+}
+
+func (testDialect) LastInsertIdSql(insertSql string) string {
+	return insertSql + " RETURNING id"
+}
+
+func TestRegisterDialect(t *testing.T) {
+	dt := RegisterDialect("MariaDB", testDialect{MySql.Dialect()})
+	parsed, err := ParseDBType("mariadb")
+	assert.Nil(t, err)
+	assert.Equal(t, dt, parsed)
+	assert.Equal(t, "mariadb", dt.DriverName())
+	assert.Equal(t, "?", dt.Param(0))
+	assert.Equal(t, "SELECT 1 RETURNING id", dt.Dialect().LastInsertIdSql("SELECT 1"))
+	assert.Equal(t, getExistsSql(MySql, "SELECT 1"), getExistsSql(dt, "SELECT 1"))
+	assert.Equal(t, dt, RegisterDialect("mariadb", testDialect{PostgreSql.Dialect()}))
+	assert.Equal(t, "$1", dt.Param(0))
+	assert.Nil(t, Type(Unknown).Dialect())
+	assert.Nil(t, Type(1000).Dialect())
+	_, err = ParseDBType("db2")
+	assert.NotNil(t, err)
+}
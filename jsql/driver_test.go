@@ -0,0 +1,201 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// testDriverName is an in-memory driver that returns the registered result of a query
+const testDriverName = "jsqltest"
+
+type testColumn struct {
+	name      string
+	dbType    string
+	scanType  reflect.Type
+	nullable  bool
+	length    int64
+	precision int64
+	scale     int64
+}
+
+type testDriverQuery struct {
+	cols []testColumn
+	rows [][]driver.Value
+}
+
+var (
+	testQueriesMux sync.Mutex
+	testQueries    = make(map[string]testDriverQuery)
+	testQueryArgs  = make(map[string][]driver.Value)
+	testExecs      []string
+	testTxOptions  driver.TxOptions
+)
+
+func init() {
+	sql.Register(testDriverName, testDriver{})
+}
+
+// testOpenDB returns a db whose query returns rows registered by testSetQuery
+func testOpenDB() *sql.DB {
+	db, _ := sql.Open(testDriverName, "")
+	return db
+}
+
+func testSetQuery(query string, cols []testColumn, rows ...[]driver.Value) {
+	testQueriesMux.Lock()
+	defer testQueriesMux.Unlock()
+	testQueries[query] = testDriverQuery{cols: cols, rows: rows}
+}
+
+// testTakeArgs returns the args of the last executed query
+func testTakeArgs(query string) []driver.Value {
+	testQueriesMux.Lock()
+	defer testQueriesMux.Unlock()
+	args := testQueryArgs[query]
+	delete(testQueryArgs, query)
+	return args
+}
+
+// testTakeTxOptions returns the options of the last begun transaction
+func testTakeTxOptions() driver.TxOptions {
+	testQueriesMux.Lock()
+	defer testQueriesMux.Unlock()
+	opts := testTxOptions
+	testTxOptions = driver.TxOptions{}
+	return opts
+}
+
+func testTakeExecs() []string {
+	testQueriesMux.Lock()
+	defer testQueriesMux.Unlock()
+	execs := testExecs
+	testExecs = nil
+	return execs
+}
+
+type testDriver struct{}
+
+func (testDriver) Open(string) (driver.Conn, error) {
+	return &testConn{}, nil
+}
+
+type testConn struct{}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return &testStmt{query: query}, nil
+}
+
+func (c *testConn) Close() error {
+	return nil
+}
+
+func (c *testConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *testConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	testQueriesMux.Lock()
+	defer testQueriesMux.Unlock()
+	testExecs = append(testExecs, "BEGIN")
+	testTxOptions = opts
+	return c, nil
+}
+
+func (c *testConn) Commit() error {
+	testQueriesMux.Lock()
+	defer testQueriesMux.Unlock()
+	testExecs = append(testExecs, "COMMIT")
+	return nil
+}
+
+func (c *testConn) Rollback() error {
+	testQueriesMux.Lock()
+	defer testQueriesMux.Unlock()
+	testExecs = append(testExecs, "ROLLBACK")
+	return nil
+}
+
+type testStmt struct {
+	query string
+}
+
+func (s *testStmt) Close() error {
+	return nil
+}
+
+func (s *testStmt) NumInput() int {
+	return -1
+}
+
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	testQueriesMux.Lock()
+	defer testQueriesMux.Unlock()
+	testExecs = append(testExecs, s.query)
+	testQueryArgs[s.query] = args
+	return driver.RowsAffected(int64(len(args))), nil
+}
+
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	testQueriesMux.Lock()
+	defer testQueriesMux.Unlock()
+	testQueryArgs[s.query] = args
+	q := testQueries[s.query]
+	return &testRows{query: q}, nil
+}
+
+type testRows struct {
+	query testDriverQuery
+	idx   int
+}
+
+func (r *testRows) Columns() []string {
+	cols := make([]string, len(r.query.cols))
+	for i, col := range r.query.cols {
+		cols[i] = col.name
+	}
+	return cols
+}
+
+func (r *testRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.query.cols[index].dbType
+}
+
+func (r *testRows) ColumnTypeScanType(index int) reflect.Type {
+	if t := r.query.cols[index].scanType; t != nil {
+		return t
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+func (r *testRows) ColumnTypeNullable(index int) (bool, bool) {
+	return r.query.cols[index].nullable, true
+}
+
+func (r *testRows) ColumnTypeLength(index int) (int64, bool) {
+	return r.query.cols[index].length, r.query.cols[index].length > 0
+}
+
+func (r *testRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	return r.query.cols[index].precision, r.query.cols[index].scale, r.query.cols[index].precision > 0
+}
+
+func (r *testRows) Close() error {
+	return nil
+}
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.idx >= len(r.query.rows) {
+		return io.EOF
+	}
+	copy(dest, r.query.rows[r.idx])
+	r.idx++
+	return nil
+}
@@ -5,21 +5,42 @@
 package jsql
 
 import (
+	"context"
 	"fmt"
 	"github.com/Knetic/govaluate"
 	"github.com/xjustloveux/jgo/jcast"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+)
+
+const (
+	// bindPrefix is the prefix of the param keys bound by <foreach> item
+	bindPrefix = "_jsql_bind"
+	// pathPrefix is the prefix of the param keys of the property paths in <if test>
+	pathPrefix = "jsqlPath"
 )
 
 type element struct {
-	id    string
-	tag   tag
-	attr  map[string]string
-	text  string
-	nodes []*element
+	id        string
+	namespace string
+	tag       tag
+	attr      map[string]string
+	text      string
+	timeout   time.Duration
+	nodes     []*element
+}
+
+// withTimeout returns ctx bounded by the element timeout attribute
+// if the element has no timeout, ctx is returned as is
+func (e *element) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if e == nil || e.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, e.timeout)
 }
 
 func (e *element) getSql(param map[string]interface{}, page bool) (string, string, error) {
@@ -36,25 +57,22 @@ func (e *element) getSql(param map[string]interface{}, page bool) (string, strin
 		fallthrough
 	case tagOther:
 		var err error
+		// bind receives the values bound by <foreach> item, the param is a copy for the dynamic tags
+		bind := param
 		if param != nil {
 			if param, err = jcast.StringMapInterface(param); err != nil {
 				return "", "", err
 			}
-			for k, v := range param {
-				if v != nil {
-					if reflect.TypeOf(v).String() == "time.Time" {
-						if param[k], err = jcast.TimeString(v); err != nil {
-							return "", "", err
-						}
-					}
-				}
-			}
 		}
 		if query, order, err = nodesToQuery(e.nodes, param, page); err != nil {
 			return "", "", err
 		}
 		if param != nil {
 			for k, v := range param {
+				if strings.HasPrefix(k, bindPrefix) {
+					bind[k] = v
+					continue
+				}
 				if v != nil {
 					if reflect.TypeOf(v).Kind() == reflect.String {
 						query = strings.ReplaceAll(query, fmt.Sprint("${", k, "}"), v.(string))
@@ -71,36 +89,55 @@ func (e *element) getSql(param map[string]interface{}, page bool) (string, strin
 	case tagText:
 		query = e.text
 	case tagIf:
-		sorTest := e.attr["test"]
-		test := ""
-		pattern := regexp.MustCompile(`nil\(\w+\)`)
-		testIdx := 0
-		for _, v := range pattern.FindAllStringSubmatchIndex(sorTest, -1) {
-			st := "nil("
-			et := ")"
-			k := sorTest[v[0]+len(st) : v[1]-len(et)]
-			test = fmt.Sprint(test, sorTest[testIdx:v[0]], strconv.FormatBool(param == nil || param[k] == nil))
-			testIdx = v[1]
-		}
-		test = fmt.Sprint(test, sorTest[testIdx:])
-		test = strings.ReplaceAll(test, " and ", " && ")
-		test = strings.ReplaceAll(test, " or ", " || ")
-		var err error
-		var expression *govaluate.EvaluableExpression
-		if expression, err = govaluate.NewEvaluableExpression(test); err != nil {
+		fallthrough
+	case tagWhen:
+		if ok, err := e.test(param); err != nil || !ok {
 			return "", "", err
 		}
-		var res interface{}
-		if res, err = expression.Evaluate(param); err != nil {
+		var err error
+		if query, order, err = nodesToQuery(e.nodes, param, page); err != nil {
 			return "", "", err
 		}
-		if reflect.TypeOf(res).Kind() != reflect.Bool || !(res.(bool)) {
-			return "", "", nil
+	case tagChoose:
+		// the first <when> whose test is true wins, otherwise the <otherwise> is used
+		var node *element
+		var otherwise *element
+		for _, n := range e.nodes {
+			if n.tag == tagWhen && node == nil {
+				if ok, err := n.test(param); err != nil {
+					return "", "", err
+				} else if ok {
+					node = n
+				}
+			} else if n.tag == tagOtherwise && otherwise == nil {
+				otherwise = n
+			}
+		}
+		if node == nil {
+			node = otherwise
+		}
+		if node != nil {
+			var err error
+			if query, order, err = nodesToQuery(node.nodes, param, page); err != nil {
+				return "", "", err
+			}
 		}
+	case tagOtherwise:
+		fallthrough
+	case tagInclude:
+		// the nodes of <include> are replaced by the <sql> nodes when loading
+		var err error
 		if query, order, err = nodesToQuery(e.nodes, param, page); err != nil {
 			return "", "", err
 		}
 	case tagForeach:
+		if e.attr["item"] != "" || e.attr["index"] != "" {
+			var err error
+			if query, order, err = e.foreachItems(param, page); err != nil {
+				return "", "", err
+			}
+			break
+		}
 		var err error
 		var text string
 		if text, order, err = nodesToQuery(e.nodes, param, page); err != nil {
@@ -150,33 +187,23 @@ func (e *element) getSql(param map[string]interface{}, page bool) (string, strin
 		}
 		query += e.attr["close"]
 	case tagWhere:
+		fallthrough
+	case tagSet:
+		fallthrough
+	case tagTrim:
 		var err error
 		if query, order, err = nodesToQuery(e.nodes, param, page); err != nil {
 			return "", "", err
 		}
-		var sw bool
-		if sw, err = regexp.MatchString(fmt.Sprint("^", e.tag.String(), "[^A-Za-z0-9_]"), strings.ToLower(query)); err != nil {
-			return "", "", err
-		}
-		if !sw {
-			var sa bool
-			if sa, err = regexp.MatchString(fmt.Sprint("^", And.String(), "[^A-Za-z0-9_]"), strings.ToUpper(query)); err != nil {
-				return "", "", err
-			}
-			if sa {
-				query = trim(query[len(And.String()):])
-			}
-			var so bool
-			if so, err = regexp.MatchString(fmt.Sprint("^", Or.String(), "[^A-Za-z0-9_]"), strings.ToUpper(query)); err != nil {
-				return "", "", err
-			}
-			if so {
-				query = trim(query[len(Or.String()):])
-			}
-			if len(query) > 0 {
-				query = fmt.Sprint("WHERE ", query)
-			}
-		}
+		prefix, suffix, prefixOverrides, suffixOverrides := e.trimAttr()
+		query = trimSql(query, prefix, suffix, prefixOverrides, suffixOverrides)
+	case tagCount:
+		// the <count> tag is only used by getCountSql
+		return "", "", nil
+	case tagProperty:
+		fallthrough
+	case tagIdentifier:
+		return "", "", nil
 	case tagOrderBy:
 		var err error
 		if query, order, err = nodesToQuery(e.nodes, param, page); err != nil {
@@ -196,6 +223,313 @@ func (e *element) getSql(param map[string]interface{}, page bool) (string, strin
 	return trim(query), trim(order), nil
 }
 
+// foreachItems renders the nodes for each item of the params attribute,
+// the @{item}, @{item.property} and @{index} in the nodes are bound as placeholders of new param keys,
+// the item and index can be used by the dynamic tags in the nodes, e.g. <if test="item != nil">
+func (e *element) foreachItems(param map[string]interface{}, page bool) (string, string, error) {
+	var val interface{}
+	if param != nil {
+		val = param[e.attr["params"]]
+	}
+	type entry struct {
+		index interface{}
+		item  interface{}
+	}
+	var entries []entry
+	if val == nil {
+		return fmt.Sprint(e.attr["open"], e.attr["close"]), "", nil
+	}
+	rv := reflect.ValueOf(val)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			entries = append(entries, entry{index: i, item: rv.Index(i).Interface()})
+		}
+	case reflect.Map:
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return jcast.String(keys[i].Interface()) < jcast.String(keys[j].Interface())
+		})
+		for _, k := range keys {
+			entries = append(entries, entry{index: k.Interface(), item: rv.MapIndex(k).Interface()})
+		}
+	default:
+		return "", "", errorFmt(errorWrongTypeOfItems, val)
+	}
+	itemName := e.attr["item"]
+	indexName := e.attr["index"]
+	// the item and index hide the param of the same name while rendering the nodes
+	oldItem, hasItem := param[itemName]
+	oldIndex, hasIndex := param[indexName]
+	defer func() {
+		restoreParam(param, itemName, oldItem, hasItem)
+		restoreParam(param, indexName, oldIndex, hasIndex)
+	}()
+	pattern := paramPattern
+	query := ""
+	order := ""
+	for i, en := range entries {
+		if itemName != "" {
+			param[itemName] = en.item
+		}
+		if indexName != "" {
+			param[indexName] = en.index
+		}
+		text, o, err := nodesToQuery(e.nodes, param, page)
+		if err != nil {
+			return "", "", err
+		}
+		if o != "" {
+			order = o
+		}
+		var bindErr error
+		text = pattern.ReplaceAllStringFunc(text, func(s string) string {
+			m := pattern.FindStringSubmatch(s)
+			var v interface{}
+			switch {
+			case m[1] == itemName && itemName != "":
+				v = en.item
+			case m[1] == indexName && indexName != "" && m[2] == "":
+				v = en.index
+			default:
+				return s
+			}
+			var err error
+			if v, err = propertyValue(v, m[2]); err != nil {
+				bindErr = err
+				return s
+			}
+			n, _ := param[bindPrefix].(int)
+			param[bindPrefix] = n + 1
+			k := fmt.Sprint(bindPrefix, n)
+			param[k] = v
+			return fmt.Sprint("@{", k, "}")
+		})
+		if bindErr != nil {
+			return "", "", bindErr
+		}
+		if i > 0 {
+			query += fmt.Sprint(e.attr["separator"], " ")
+		}
+		query += trim(text)
+	}
+	return fmt.Sprint(e.attr["open"], query, e.attr["close"]), order, nil
+}
+
+func restoreParam(param map[string]interface{}, key string, val interface{}, ok bool) {
+	if key == "" {
+		return
+	}
+	if ok {
+		param[key] = val
+	} else {
+		delete(param, key)
+	}
+}
+
+// copyWith returns a deep copy of e, the ${name} in the text and attributes are replaced by props
+func (e *element) copyWith(props map[string]string) *element {
+	replace := func(str string) string {
+		for k, v := range props {
+			str = strings.ReplaceAll(str, fmt.Sprint("${", k, "}"), v)
+		}
+		return str
+	}
+	c := &element{id: e.id, namespace: e.namespace, tag: e.tag, text: replace(e.text), timeout: e.timeout}
+	if e.attr != nil {
+		c.attr = make(map[string]string, len(e.attr))
+		for k, v := range e.attr {
+			c.attr[k] = replace(v)
+		}
+	}
+	if e.nodes != nil {
+		c.nodes = make([]*element, 0, len(e.nodes))
+		for _, n := range e.nodes {
+			c.nodes = append(c.nodes, n.copyWith(props))
+		}
+	}
+	return c
+}
+
+// trimAttr returns the prefix, suffix, prefixOverrides and suffixOverrides of <trim>,
+// <where> and <set> are the <trim> with fixed attributes
+func (e *element) trimAttr() (prefix, suffix, prefixOverrides, suffixOverrides string) {
+	switch e.tag {
+	case tagWhere:
+		return "WHERE", "", fmt.Sprint("WHERE|", And.String(), "|", Or.String()), ""
+	case tagSet:
+		return "SET", "", ",", ","
+	}
+	return e.attr["prefix"], e.attr["suffix"], e.attr["prefixoverrides"], e.attr["suffixoverrides"]
+}
+
+// trimSql removes the first matched prefixOverrides and suffixOverrides of query, then adds prefix and suffix
+// the overrides are separated by |, e.g. AND|OR, the empty query returns empty
+func trimSql(query, prefix, suffix, prefixOverrides, suffixOverrides string) string {
+	query = trim(query)
+	if prefixOverrides != "" {
+		for _, o := range strings.Split(prefixOverrides, "|") {
+			if o = trim(o); o != "" && len(query) >= len(o) && strings.EqualFold(query[:len(o)], o) &&
+				(!isWordChar(o[len(o)-1]) || len(query) == len(o) || !isWordChar(query[len(o)])) {
+				query = trim(query[len(o):])
+				break
+			}
+		}
+	}
+	if suffixOverrides != "" {
+		for _, o := range strings.Split(suffixOverrides, "|") {
+			if o = trim(o); o != "" && len(query) >= len(o) && strings.EqualFold(query[len(query)-len(o):], o) &&
+				(!isWordChar(o[0]) || len(query) == len(o) || !isWordChar(query[len(query)-len(o)-1])) {
+				query = trim(query[:len(query)-len(o)])
+				break
+			}
+		}
+	}
+	if query == "" {
+		return ""
+	}
+	if prefix = trim(prefix); prefix != "" {
+		query = fmt.Sprint(prefix, " ", query)
+	}
+	if suffix = trim(suffix); suffix != "" {
+		query = fmt.Sprint(query, " ", suffix)
+	}
+	return query
+}
+
+// test evaluates the test attribute of <if> and <when>, nil(key) checks whether the param key is nil
+func (e *element) test(param map[string]interface{}) (bool, error) {
+	sorTest := e.attr["test"]
+	test := ""
+	pattern := regexp.MustCompile(`nil\((\w+)((?:\.\w+|\[\d+\])*)\)`)
+	testIdx := 0
+	for _, v := range pattern.FindAllStringSubmatchIndex(sorTest, -1) {
+		var val interface{}
+		if param != nil {
+			var err error
+			if val, err = propertyValue(param[sorTest[v[2]:v[3]]], sorTest[v[4]:v[5]]); err != nil {
+				return false, err
+			}
+		}
+		test = fmt.Sprint(test, sorTest[testIdx:v[0]], strconv.FormatBool(val == nil))
+		testIdx = v[1]
+	}
+	test = fmt.Sprint(test, sorTest[testIdx:])
+	test = strings.ReplaceAll(test, " and ", " && ")
+	test = strings.ReplaceAll(test, " or ", " || ")
+	test, param, err := replacePaths(test, param)
+	if err != nil {
+		return false, err
+	}
+	if param, err = timeStringParam(param); err != nil {
+		return false, err
+	}
+	expression, err := govaluate.NewEvaluableExpression(test)
+	if err != nil {
+		return false, err
+	}
+	var res interface{}
+	if res, err = expression.Evaluate(param); err != nil {
+		return false, err
+	}
+	b, ok := res.(bool)
+	return ok && b, nil
+}
+
+// timeStringParam returns a copy of param whose time.Time values are converted to string for the expression
+// the param is returned as is if it has no time.Time value
+func timeStringParam(param map[string]interface{}) (map[string]interface{}, error) {
+	var cp map[string]interface{}
+	for k, v := range param {
+		if _, ok := v.(time.Time); !ok {
+			continue
+		}
+		if cp == nil {
+			cp = make(map[string]interface{}, len(param))
+			for ck, cv := range param {
+				cp[ck] = cv
+			}
+		}
+		var err error
+		if cp[k], err = jcast.TimeString(v); err != nil {
+			return nil, err
+		}
+	}
+	if cp == nil {
+		return param, nil
+	}
+	return cp, nil
+}
+
+// replacePaths replaces the property paths outside the quoted strings of test, e.g. user.address.city or items[0].id,
+// by generated param names and returns the test and a copy of param with the values of the paths
+func replacePaths(test string, param map[string]interface{}) (string, map[string]interface{}, error) {
+	res := ""
+	n := 0
+	var cp map[string]interface{}
+	for seg, idx := 0, 0; idx <= len(test); idx++ {
+		if idx < len(test) && test[idx] != '\'' && test[idx] != '"' {
+			continue
+		}
+		part := test[seg:idx]
+		partIdx := 0
+		for _, v := range pathPattern.FindAllStringIndex(part, -1) {
+			if v[0] > 0 && isWordChar(part[v[0]-1]) {
+				continue
+			}
+			if cp == nil {
+				cp = make(map[string]interface{}, len(param)+1)
+				for k, val := range param {
+					cp[k] = val
+				}
+			}
+			path := part[v[0]:v[1]]
+			i := strings.IndexAny(path, ".[")
+			val, err := propertyValue(param[path[:i]], path[i:])
+			if err != nil {
+				return "", nil, err
+			}
+			name := fmt.Sprint(pathPrefix, n)
+			n++
+			cp[name] = val
+			res = fmt.Sprint(res, part[partIdx:v[0]], name)
+			partIdx = v[1]
+		}
+		res = fmt.Sprint(res, part[partIdx:])
+		if idx == len(test) {
+			break
+		}
+		// copy the quoted string
+		end := strings.IndexByte(test[idx+1:], test[idx])
+		if end < 0 {
+			end = len(test) - idx - 1
+		} else {
+			end++
+		}
+		res = fmt.Sprint(res, test[idx:idx+1+end])
+		idx += end
+		seg = idx + 1
+	}
+	if cp == nil {
+		return test, param, nil
+	}
+	return res, cp, nil
+}
+
+// getCountSql returns the count query of the <count> child tag, the query is empty if there is no <count> tag
+func (e *element) getCountSql(param map[string]interface{}) (string, error) {
+	for _, node := range e.nodes {
+		if node.tag == tagCount {
+			query, _, err := (&element{tag: tagOther, nodes: node.nodes}).getSql(param, false)
+			return query, err
+		}
+	}
+	return "", nil
+}
+
 func nodesToQuery(nodes []*element, param map[string]interface{}, page bool) (string, string, error) {
 	query := ""
 	order := ""
@@ -0,0 +1,372 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestElement_WithTimeout(t *testing.T) {
+	ctx, cancel := (&element{}).withTimeout(context.Background())
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+	cancel()
+	ctx, cancel = (&element{timeout: time.Minute}).withTimeout(context.Background())
+	defer cancel()
+	_, ok = ctx.Deadline()
+	assert.True(t, ok)
+}
+
+// testDao parses the xml string and returns the dao element
+func testDao(t *testing.T, xml string) *element {
+	path := filepath.Join(t.TempDir(), "dao.xml")
+	assert.Nil(t, os.WriteFile(path, []byte(xml), 0644))
+	dao, err := toElement(path)
+	assert.Nil(t, err)
+	return dao
+}
+
+func TestToElement_WrongParentOfWhen(t *testing.T) {
+	tests := []struct {
+		xml string
+		err error
+	}{
+		{`<dao>
+    <select id="a">SELECT * FROM T <when test="!nil(a)">WHERE A = @{a}</when></select>
+</dao>`, errorFmt(errorWrongParentOfTag, "when", "select", "a")},
+		{`<dao namespace="user">
+    <select id="b">
+        SELECT * FROM T
+        <where><otherwise>A = 1</otherwise></where>
+    </select>
+</dao>`, errorFmt(errorWrongParentOfTag, "otherwise", "where", "user.b")},
+		{`<dao>
+    <select id="c">
+        SELECT * FROM T
+        <where>
+            <choose>
+                <when test="!nil(a)">A = @{a}</when>
+                <otherwise>A = 1</otherwise>
+            </choose>
+        </where>
+    </select>
+</dao>`, nil},
+	}
+	for _, v := range tests {
+		path := filepath.Join(t.TempDir(), "dao.xml")
+		assert.Nil(t, os.WriteFile(path, []byte(v.xml), 0644))
+		_, err := toElement(path)
+		assert.Equal(t, v.err, err)
+	}
+}
+
+func TestElement_GetCountSql(t *testing.T) {
+	dao := testDao(t, `<dao>
+    <select id="page">
+        SELECT * FROM T WHERE A = @{a}
+        <count>
+            SELECT COUNT(1) FROM T
+            <if test="!nil(a)">WHERE A = @{a}</if>
+        </count>
+        <orderBy last="true">ID</orderBy>
+    </select>
+    <select id="noCount">SELECT * FROM T</select>
+</dao>`)
+	param := map[string]interface{}{"a": "x"}
+	query, order, err := dao.nodes[0].getSql(param, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM T WHERE A = @{a}", query)
+	assert.Equal(t, "ID", order)
+	count, err := dao.nodes[0].getCountSql(param)
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT COUNT(1) FROM T WHERE A = @{a}", count)
+	count, err = dao.nodes[1].getCountSql(param)
+	assert.Nil(t, err)
+	assert.Equal(t, "", count)
+}
+
+func TestElement_GetSql_Choose(t *testing.T) {
+	dao := testDao(t, `<dao>
+    <select id="choose">
+        SELECT * FROM T
+        <where>
+            <choose>
+                <when test="!nil(id)">AND ID = @{id}</when>
+                <when test="!nil(name)">AND NAME = @{name}</when>
+                <otherwise>AND STATUS = 1</otherwise>
+            </choose>
+        </where>
+        ORDER BY
+        <foreach params="cols" separator=",">
+            #{val}
+            <choose>
+                <when test="desc">DESC</when>
+            </choose>
+        </foreach>
+    </select>
+</dao>`)
+	tests := []struct {
+		param map[string]interface{}
+		out   string
+	}{
+		{map[string]interface{}{"id": 1, "name": "a", "desc": true, "cols": []string{"A"}},
+			"SELECT * FROM T WHERE ID = @{id} ORDER BY A DESC"},
+		{map[string]interface{}{"name": "a", "desc": false, "cols": []string{"A", "B"}},
+			"SELECT * FROM T WHERE NAME = @{name} ORDER BY A, B"},
+		{map[string]interface{}{"desc": false, "cols": []string{"A"}},
+			"SELECT * FROM T WHERE STATUS = 1 ORDER BY A"},
+	}
+	for _, v := range tests {
+		query, _, err := dao.nodes[0].getSql(v.param, false)
+		assert.Nil(t, err)
+		assert.Equal(t, v.out, query)
+	}
+}
+
+func TestTrimSql(t *testing.T) {
+	tests := []struct {
+		query           string
+		prefix          string
+		suffix          string
+		prefixOverrides string
+		suffixOverrides string
+		out             string
+	}{
+		{"AND A = 1", "WHERE", "", "WHERE|AND|OR", "", "WHERE A = 1"},
+		{"or A = 1", "WHERE", "", "WHERE|AND|OR", "", "WHERE A = 1"},
+		{"WHERE A = 1", "WHERE", "", "WHERE|AND|OR", "", "WHERE A = 1"},
+		{"ANDROID = 1", "WHERE", "", "WHERE|AND|OR", "", "WHERE ANDROID = 1"},
+		{"", "WHERE", "", "WHERE|AND|OR", "", ""},
+		{"A = 1, B = 2,", "SET", "", ",", ",", "SET A = 1, B = 2"},
+		{",", "SET", "", ",", ",", ""},
+		{"A, B,", "(", ")", "", ",", "( A, B )"},
+	}
+	for _, v := range tests {
+		out := trimSql(v.query, v.prefix, v.suffix, v.prefixOverrides, v.suffixOverrides)
+		assert.Equal(t, v.out, out)
+	}
+}
+
+func TestElement_GetSql_SetTrim(t *testing.T) {
+	dao := testDao(t, `<dao>
+    <update id="set">
+        UPDATE T
+        <set>
+            <if test="!nil(a)">A = @{a},</if>
+            <if test="!nil(b)">B = @{b},</if>
+        </set>
+        <where>
+            <if test="!nil(id)">AND ID = @{id}</if>
+        </where>
+    </update>
+    <insert id="trim">
+        INSERT INTO T
+        <trim prefix="(" suffix=")" suffixOverrides=",">
+            <if test="!nil(a)">A,</if>
+            <if test="!nil(b)">B,</if>
+        </trim>
+        VALUES
+        <trim prefix="(" suffix=")" suffixOverrides=",">
+            <if test="!nil(a)">@{a},</if>
+            <if test="!nil(b)">@{b},</if>
+        </trim>
+    </insert>
+</dao>`)
+	query, _, err := dao.nodes[0].getSql(map[string]interface{}{"a": 1, "id": 1}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE T SET A = @{a} WHERE ID = @{id}", query)
+	query, _, err = dao.nodes[0].getSql(map[string]interface{}{"a": 1, "b": 2}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE T SET A = @{a}, B = @{b}", query)
+	query, _, err = dao.nodes[1].getSql(map[string]interface{}{"a": 1, "b": 2}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT INTO T ( A, B ) VALUES ( @{a}, @{b} )", query)
+}
+
+func TestLoadDao_Include(t *testing.T) {
+	common := testDao(t, `<dao>
+    <sql id="cols">${alias}.ID, ${alias}.NAME</sql>
+    <sql id="where">
+        <where>
+            <if test="!nil(id)">AND ${alias}.ID = @{id}</if>
+        </where>
+    </sql>
+</dao>`)
+	user := testDao(t, `<dao>
+    <select id="includeUser">
+        SELECT
+        <include refid="cols"><property name="alias" value="U"/></include>
+        FROM USERS U
+        <include refid="where"><property name="alias" value="U"/></include>
+    </select>
+</dao>`)
+	assert.Nil(t, loadDao([]*element{common, user}))
+	elem, err := getElement(Select, "includeUser")
+	assert.Nil(t, err)
+	query, _, err := elem.getSql(map[string]interface{}{"id": 1}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT U.ID, U.NAME FROM USERS U WHERE U.ID = @{id}", query)
+
+	missing := testDao(t, `<dao><select id="missing">SELECT <include refid="none"/> FROM T</select></dao>`)
+	err = loadDao([]*element{common, missing})
+	assert.EqualError(t, err, `jsql: unknown sql refid "none" of include in select id "missing"`)
+
+	circular := testDao(t, `<dao>
+    <sql id="a">A <include refid="b"/></sql>
+    <sql id="b">B <include refid="a"/></sql>
+</dao>`)
+	err = loadDao([]*element{circular})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "circular include")
+}
+
+func TestLoadDao_Namespace(t *testing.T) {
+	user := testDao(t, `<dao namespace="user">
+    <sql id="cols">ID, NAME</sql>
+    <select id="list">SELECT <include refid="cols"/> FROM USERS</select>
+</dao>`)
+	order := testDao(t, `<dao namespace="order">
+    <sql id="cols">ID, USER_ID</sql>
+    <select id="list">SELECT <include refid="cols"/>, <include refid="user.cols"/> FROM ORDERS</select>
+</dao>`)
+	assert.Nil(t, loadDao([]*element{user, order}))
+	tests := []struct {
+		id  string
+		out string
+	}{
+		{"user.list", "SELECT ID, NAME FROM USERS"},
+		{"order.list", "SELECT ID, USER_ID , ID, NAME FROM ORDERS"},
+	}
+	for _, v := range tests {
+		elem, err := getElement(Select, v.id)
+		assert.Nil(t, err)
+		query, _, err := elem.getSql(nil, false)
+		assert.Nil(t, err)
+		assert.Equal(t, v.out, query)
+	}
+	_, err := getElement(Select, "list")
+	assert.NotNil(t, err)
+
+	dup := testDao(t, `<dao namespace="user"><select id="list">SELECT 1</select></dao>`)
+	err = loadDao([]*element{user, dup})
+	assert.EqualError(t, err, `jsql: duplicate select id "user.list"`)
+}
+
+func TestElement_GetSql_ForeachItem(t *testing.T) {
+	dao := testDao(t, `<dao>
+    <select id="in">
+        SELECT * FROM T WHERE ID IN
+        <foreach params="ids" item="id" open="(" separator="," close=")">@{id}</foreach>
+        AND NAME = @{name}
+    </select>
+    <insert id="batch">
+        INSERT INTO T (ID, NAME, SEQ) VALUES
+        <foreach params="rows" item="row" index="i" separator=",">
+            (@{row.id}, <if test="nil(row)">NULL</if><if test="!nil(row)">@{row.name}</if>, @{i})
+        </foreach>
+    </insert>
+</dao>`)
+	type row struct {
+		ID   int    `jsql:"ID"`
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		t     Type
+		elem  *element
+		param map[string]interface{}
+		query string
+		args  []interface{}
+	}{
+		{MSSql, dao.nodes[0], map[string]interface{}{"ids": []int{1, 2}, "name": "a' OR 1 = 1"},
+			"SELECT * FROM T WHERE ID IN (@p1, @p2) AND NAME = @p3", []interface{}{1, 2, "a' OR 1 = 1"}},
+		{MySql, dao.nodes[0], map[string]interface{}{"ids": nil, "name": "a"},
+			"SELECT * FROM T WHERE ID IN () AND NAME = ?", []interface{}{"a"}},
+		{PostgreSql, dao.nodes[1], map[string]interface{}{"rows": []row{{1, "a"}, {2, "b"}}},
+			"INSERT INTO T (ID, NAME, SEQ) VALUES ($1, $2 , $3), ($4, $5 , $6)",
+			[]interface{}{1, "a", 0, 2, "b", 1}},
+		{MySql, dao.nodes[1], map[string]interface{}{"rows": []map[string]interface{}{{"id": 3, "name": "c"}}},
+			"INSERT INTO T (ID, NAME, SEQ) VALUES (?, ? , ?)", []interface{}{3, "c", 0}},
+	}
+	for _, v := range tests {
+		query, _, err := v.elem.getSql(v.param, false)
+		assert.Nil(t, err)
+		query, args, err := (&Agent{t: v.t}).getQueryAndArgs(query, v.param)
+		assert.Nil(t, err)
+		assert.Equal(t, v.query, query)
+		assert.Equal(t, v.args, args)
+	}
+	_, _, err := dao.nodes[0].getSql(map[string]interface{}{"ids": 1}, false)
+	assert.NotNil(t, err)
+}
+
+func TestElement_GetSql_PropertyPath(t *testing.T) {
+	dao := testDao(t, `<dao>
+    <select id="A">
+        SELECT * FROM T
+        <where>
+            <if test="!nil(user.address.city)">AND CITY = @{user.address.city}</if>
+            <if test="items[0].id > 1 and user.name == 'a.b'">AND ID = @{items[0].id}</if>
+            <if test="!nil(items[1])">AND ID2 = @{items[1].id}</if>
+        </where>
+    </select>
+</dao>`)
+	type address struct {
+		City string
+	}
+	type user struct {
+		Name    string
+		Address *address
+	}
+	items := []map[string]interface{}{{"id": 2}}
+	tests := []struct {
+		param map[string]interface{}
+		query string
+		args  []interface{}
+	}{
+		{map[string]interface{}{"user": user{Name: "a.b", Address: &address{City: "c"}}, "items": items},
+			"SELECT * FROM T WHERE CITY = ? AND ID = ?", []interface{}{"c", 2}},
+		{map[string]interface{}{"user": map[string]interface{}{"Name": "x"}, "items": items},
+			"SELECT * FROM T", []interface{}{}},
+		{map[string]interface{}{"user": &user{Name: "a.b"}, "items": []map[string]interface{}{{"id": 1}}},
+			"SELECT * FROM T", []interface{}{}},
+	}
+	for _, v := range tests {
+		query, _, err := dao.nodes[0].getSql(v.param, false)
+		assert.Nil(t, err)
+		query, args, err := (&Agent{t: MySql}).getQueryAndArgs(trim(query), v.param)
+		assert.Nil(t, err)
+		assert.Equal(t, v.query, query)
+		assert.Equal(t, v.args, args)
+	}
+	_, _, err := dao.nodes[0].getSql(map[string]interface{}{"user": 1}, false)
+	assert.NotNil(t, err)
+	_, _, err = (&Agent{t: MySql}).getQueryAndArgs("@{items.id}", map[string]interface{}{"items": items})
+	assert.NotNil(t, err)
+}
+
+func TestElement_GetSql_Time(t *testing.T) {
+	dao := testDao(t, `<dao>
+    <select id="A">
+        SELECT * FROM T
+        <where>
+            <if test="!nil(rows[0].created) and created != ''">CREATED > @{created}</if>
+            <foreach params="rows" item="row" open="AND UPDATED IN (" separator="," close=")">@{row.created}</foreach>
+        </where>
+    </select>
+</dao>`)
+	tm := time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC)
+	param := map[string]interface{}{"created": tm, "rows": []map[string]interface{}{{"created": tm}}}
+	query, _, err := dao.nodes[0].getSql(param, false)
+	assert.Nil(t, err)
+	query, args, err := (&Agent{t: MySql}).getQueryAndArgs(trim(query), param)
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM T WHERE CREATED > ? AND UPDATED IN (?)", query)
+	assert.Equal(t, []interface{}{tm, tm}, args)
+}
@@ -0,0 +1,145 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Querier is implemented by *Agent and *Tx, it is used by the typed query functions
+type Querier interface {
+	QueryContext(ctx context.Context, id string, args ...interface{}) (Result, error)
+	QueryRowContext(ctx context.Context, id string, args ...interface{}) (Result, error)
+	QueryPageContext(ctx context.Context, id string, start, end int64, args ...interface{}) (Result, error)
+	QueryPagingContext(ctx context.Context, id string, p Paging, args ...interface{}) (Result, error)
+	QueryIterContext(ctx context.Context, id string, args ...interface{}) (*Rows, error)
+}
+
+// Page is the typed result of QueryPage and QueryPaging
+type Page[T any] struct {
+	Rows        []T
+	RowStart    int64
+	RowEnd      int64
+	TotalRecord int64
+	PageInfo    PageInfo
+}
+
+// QueryAll executes the xml select tag id and returns all rows as T
+// the args are for any placeholder parameters in the query
+func QueryAll[T any](q Querier, id string, args ...interface{}) ([]T, error) {
+	return QueryAllContext[T](context.Background(), q, id, args...)
+}
+
+// QueryAllContext executes the xml select tag id with context and returns all rows as T
+// the args are for any placeholder parameters in the query
+func QueryAllContext[T any](ctx context.Context, q Querier, id string, args ...interface{}) ([]T, error) {
+	res, err := q.QueryContext(ctx, id, args...)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]T, 0, len(res.Rows()))
+	if err = mapTo(res.Rows(), &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// QueryOne executes the xml select tag id and returns the first row as T
+// if there is no row, sql.ErrNoRows is returned
+func QueryOne[T any](q Querier, id string, args ...interface{}) (T, error) {
+	return QueryOneContext[T](context.Background(), q, id, args...)
+}
+
+// QueryOneContext executes the xml select tag id with context and returns the first row as T
+// if there is no row, sql.ErrNoRows is returned
+func QueryOneContext[T any](ctx context.Context, q Querier, id string, args ...interface{}) (T, error) {
+	var v T
+	res, err := q.QueryRowContext(ctx, id, args...)
+	if err != nil {
+		return v, err
+	}
+	if res.Row() == nil {
+		return v, sql.ErrNoRows
+	}
+	err = mapTo(res.Row(), &v)
+	return v, err
+}
+
+// QueryPage executes the xml select tag id and returns rows from start to end as T
+func QueryPage[T any](q Querier, id string, start, end int64, args ...interface{}) (*Page[T], error) {
+	return QueryPageContext[T](context.Background(), q, id, start, end, args...)
+}
+
+// QueryPageContext executes the xml select tag id with context and returns rows from start to end as T
+func QueryPageContext[T any](ctx context.Context, q Querier, id string, start, end int64, args ...interface{}) (*Page[T], error) {
+	res, err := q.QueryPageContext(ctx, id, start, end, args...)
+	if err != nil {
+		return nil, err
+	}
+	return toPage[T](res)
+}
+
+// QueryPaging executes the xml select tag id and returns the rows of page number p.Page as T
+func QueryPaging[T any](q Querier, id string, p Paging, args ...interface{}) (*Page[T], error) {
+	return QueryPagingContext[T](context.Background(), q, id, p, args...)
+}
+
+// QueryPagingContext executes the xml select tag id with context and returns the rows of page number p.Page as T
+func QueryPagingContext[T any](ctx context.Context, q Querier, id string, p Paging, args ...interface{}) (*Page[T], error) {
+	res, err := q.QueryPagingContext(ctx, id, p, args...)
+	if err != nil {
+		return nil, err
+	}
+	return toPage[T](res)
+}
+
+func toPage[T any](res Result) (*Page[T], error) {
+	page := &Page[T]{
+		Rows:        make([]T, 0, len(res.Rows())),
+		RowStart:    res.RowStart(),
+		RowEnd:      res.RowEnd(),
+		TotalRecord: res.TotalRecord(),
+	}
+	if pr, ok := res.(PagingResult); ok {
+		page.PageInfo = pr.PageInfo()
+	}
+	if err := mapTo(res.Rows(), &page.Rows); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// QueryScalar executes the xml select tag id and returns the first column of the first row as T
+// if there is no row, sql.ErrNoRows is returned
+func QueryScalar[T any](q Querier, id string, args ...interface{}) (T, error) {
+	return QueryScalarContext[T](context.Background(), q, id, args...)
+}
+
+// QueryScalarContext executes the xml select tag id with context and returns the first column of the first row as T
+// if there is no row, sql.ErrNoRows is returned
+func QueryScalarContext[T any](ctx context.Context, q Querier, id string, args ...interface{}) (v T, err error) {
+	var rows *Rows
+	if rows, err = q.QueryIterContext(ctx, id, args...); err != nil {
+		return v, err
+	}
+	defer func() {
+		if e := rows.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return v, err
+		}
+		return v, sql.ErrNoRows
+	}
+	cols := rows.Columns()
+	if len(cols) == 0 {
+		return v, sql.ErrNoRows
+	}
+	err = mapTo(rows.Record()[cols[0]], &v)
+	return v, err
+}
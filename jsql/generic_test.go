@@ -0,0 +1,95 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+var (
+	_ Querier = (*Agent)(nil)
+	_ Querier = (*Tx)(nil)
+)
+
+// testQuerier returns rows of the fixed result, the QueryIterContext uses id as sql
+type testQuerier struct {
+	agent  *Agent
+	result agentResult
+}
+
+func (q testQuerier) QueryContext(context.Context, string, ...interface{}) (Result, error) {
+	return q.result, nil
+}
+
+func (q testQuerier) QueryRowContext(context.Context, string, ...interface{}) (Result, error) {
+	if len(q.result.rows) > 1 {
+		return agentResult{rows: q.result.rows[:1]}, nil
+	}
+	return q.result, nil
+}
+
+func (q testQuerier) QueryPageContext(context.Context, string, int64, int64, ...interface{}) (Result, error) {
+	return q.result, nil
+}
+
+func (q testQuerier) QueryPagingContext(context.Context, string, Paging, ...interface{}) (Result, error) {
+	return q.result, nil
+}
+
+func (q testQuerier) QueryIterContext(ctx context.Context, id string, args ...interface{}) (*Rows, error) {
+	return q.agent.QueryIterWithSqlContext(ctx, id, args...)
+}
+
+func TestQueryGeneric(t *testing.T) {
+	type data struct {
+		ID   int64  `jsql:"ID"`
+		Name string `jsql:"NAME"`
+	}
+	q := testQuerier{
+		agent: &Agent{db: testOpenDB(), t: MySql},
+		result: agentResult{
+			rows: []map[string]interface{}{
+				{"ID": int64(1), "NAME": "A"},
+				{"ID": int64(2), "NAME": "B"},
+			},
+			rowStart:    1,
+			rowEnd:      2,
+			totalRecord: 5,
+		},
+	}
+	list, err := QueryAll[data](q, "id")
+	assert.Nil(t, err)
+	assert.Equal(t, []data{{1, "A"}, {2, "B"}}, list)
+
+	one, err := QueryOne[data](q, "id")
+	assert.Nil(t, err)
+	assert.Equal(t, data{1, "A"}, one)
+	_, err = QueryOne[data](testQuerier{}, "id")
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	page, err := QueryPage[data](q, "id", 1, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, &Page[data]{Rows: list, RowStart: 1, RowEnd: 2, TotalRecord: 5}, page)
+
+	testSetQuery("SELECT COUNT(1) AS C, 'X' AS X", []testColumn{
+		{name: "C", dbType: "BIGINT", scanType: reflect.TypeOf(int64(0))},
+		{name: "X", dbType: "VARCHAR"},
+	}, []driver.Value{int64(7), []byte("X")})
+	count, err := QueryScalar[int](q, "SELECT COUNT(1) AS C, 'X' AS X")
+	assert.Nil(t, err)
+	assert.Equal(t, 7, count)
+	testSetQuery("SELECT NULL AS C", []testColumn{{name: "C", dbType: "BIGINT"}}, []driver.Value{nil})
+	ptr, err := QueryScalar[*int](q, "SELECT NULL AS C")
+	assert.Nil(t, err)
+	assert.Nil(t, ptr)
+	testSetQuery("SELECT EMPTY", []testColumn{{name: "C", dbType: "BIGINT"}})
+	_, err = QueryScalar[int](q, "SELECT EMPTY")
+	assert.Equal(t, sql.ErrNoRows, err)
+}
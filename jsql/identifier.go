@@ -0,0 +1,89 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var (
+	// identifierPattern matches !{key} and !{key.property}, the submatches are key and property path
+	identifierPattern = regexp.MustCompile(`!\{(\w+)((?:\.\w+|\[\d+\])*)\}`)
+	// identifierGrammar matches the identifier, e.g. COL or T.COL
+	identifierGrammar = regexp.MustCompile(`^[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*$`)
+)
+
+// identifiers returns the allow lists of the <identifier> tags in e, the key is the name attr
+func (e *element) identifiers() map[string][]string {
+	m := make(map[string][]string)
+	var walk func(nodes []*element)
+	walk = func(nodes []*element) {
+		for _, node := range nodes {
+			if node.tag == tagIdentifier {
+				allow := make([]string, 0)
+				for _, v := range strings.Split(node.attr["allow"], "|") {
+					if v = trim(v); v != "" {
+						allow = append(allow, v)
+					}
+				}
+				m[node.attr["name"]] = allow
+			}
+			walk(node.nodes)
+		}
+	}
+	walk(e.nodes)
+	return m
+}
+
+// replaceIdentifiers replaces !{key} in query by the quoted identifier of db Type
+// the value must be in the allow list of the <identifier> tag named key, or match the identifier grammar if there is no such tag
+func (e *element) replaceIdentifiers(t Type, query string, param map[string]interface{}) (string, error) {
+	matches := identifierPattern.FindAllStringSubmatchIndex(query, -1)
+	if len(matches) <= 0 {
+		return query, nil
+	}
+	allows := e.identifiers()
+	res := ""
+	queryIdx := 0
+	for _, v := range matches {
+		k := query[v[2]:v[3]]
+		val, err := propertyValue(param[k], query[v[4]:v[5]])
+		if err != nil {
+			return "", err
+		}
+		name := query[v[2]:v[5]]
+		str, ok := val.(string)
+		if !ok && val != nil && reflect.TypeOf(val).Kind() == reflect.String {
+			str, ok = reflect.ValueOf(val).String(), true
+		}
+		if !ok {
+			return "", errorFmt(errorWrongIdentifier, val, name)
+		}
+		if allow, ok := allows[name]; ok {
+			found := false
+			for _, a := range allow {
+				if strings.EqualFold(a, str) {
+					str, found = a, true
+					break
+				}
+			}
+			if !found {
+				return "", errorFmt(errorIdentifierNotAllow, str, name)
+			}
+		} else if !identifierGrammar.MatchString(str) {
+			return "", errorFmt(errorWrongIdentifier, str, name)
+		}
+		parts := strings.Split(str, ".")
+		for i, p := range parts {
+			parts[i] = t.QuoteIdentifier(p)
+		}
+		res = fmt.Sprint(res, query[queryIdx:v[0]], strings.Join(parts, "."))
+		queryIdx = v[1]
+	}
+	return fmt.Sprint(res, query[queryIdx:]), nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestType_QuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		t    Type
+		name string
+		out  string
+	}{
+		{MySql, "COL", "`COL`"},
+		{MySql, "C`L", "`C``L`"},
+		{MSSql, "C]L", "[C]]L]"},
+		{Oracle, "COL", `"COL"`},
+		{PostgreSql, `C"L`, `"C""L"`},
+		{Sqlite, "COL", `"COL"`},
+		{Unknown, "COL", `"COL"`},
+	}
+	for _, v := range tests {
+		assert.Equal(t, v.out, v.t.QuoteIdentifier(v.name))
+	}
+}
+
+func TestElement_ReplaceIdentifiers(t *testing.T) {
+	dao := testDao(t, `<dao>
+    <select id="A">
+        <identifier name="sort.col" allow="ID|T.CREATED_AT"/>
+        SELECT * FROM !{table} T
+        <orderBy last="true">!{sort.col} DESC</orderBy>
+    </select>
+</dao>`)
+	tests := []struct {
+		t     Type
+		param map[string]interface{}
+		query string
+		order string
+		err   bool
+	}{
+		{MySql, map[string]interface{}{"table": "USERS", "sort": map[string]interface{}{"col": "id"}},
+			"SELECT * FROM `USERS` T", "`ID` DESC", false},
+		{MSSql, map[string]interface{}{"table": "dbo.USERS", "sort": map[string]interface{}{"col": "t.created_at"}},
+			"SELECT * FROM [dbo].[USERS] T", "[T].[CREATED_AT] DESC", false},
+		{PostgreSql, map[string]interface{}{"table": "USERS; DROP TABLE USERS", "sort": map[string]interface{}{"col": "ID"}},
+			"", "", true},
+		{Oracle, map[string]interface{}{"table": "USERS", "sort": map[string]interface{}{"col": "NAME"}},
+			"", "", true},
+		{Sqlite, map[string]interface{}{"table": 1, "sort": map[string]interface{}{"col": "ID"}},
+			"", "", true},
+		{Sqlite, nil, "", "", true},
+	}
+	for _, v := range tests {
+		query, order, err := (&Agent{t: v.t}).getSql(dao.nodes[0], v.param, true)
+		assert.Equal(t, v.err, err != nil, v.param)
+		assert.Equal(t, v.query, query)
+		assert.Equal(t, v.order, order)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -27,9 +28,15 @@ const (
 
 	errorColTypeNotStringType = jError("column name type is %q, not string")
 	errorColNil               = jError("column is nil")
+	errorBatchRowsEmpty       = jError("batch rows is empty")
+	errorBatchRowColumns      = jError("columns of batch row %d are not the same as the first row")
+	errorBatchRowParams       = jError("batch row has %d columns, more than the %d bind parameters of a statement")
+	errorUpsertConflictEmpty  = jError("upsert conflict columns is empty")
+	errorUpsertColumn         = jError("upsert column %s is not in the insert columns")
 
-	errorNotValidDbType    = jError("not a valid db Type %q")
-	errorNotValidOperators = jError("not a valid Operators %q")
+	errorNotValidDbType      = jError("not a valid db Type %q")
+	errorNotValidOperators   = jError("not a valid Operators %q")
+	errorNotValidDecimalMode = jError("not a valid DecimalMode %q")
 
 	errorUnknownDataSource            = jError("unknown data source %q")
 	errorUnknownSelectId              = jError("unknown select id %q")
@@ -37,9 +44,12 @@ const (
 	errorUnknownUpdateId              = jError("unknown update id %q")
 	errorUnknownDeleteId              = jError("unknown delete id %q")
 	errorUnknownOtherId               = jError("unknown other id %q")
+	errorUnknownSqlRefId              = jError("unknown sql refid %q of include in %s id %q")
 	errorUnknownOps                   = jError("unknown Operations")
 	errorUnknownOpr                   = jError("unknown Operators")
 	errorUnknownSqlTypeForAgentTables = jError("unknown sql type, you can use args input query statement")
+	errorUnknownSqlTypeForSavepoint   = jError("unknown sql type, savepoint is not supported")
+	errorUnknownSqlTypeForBatch       = jError("unknown sql type, batch insert and upsert are not supported")
 
 	errorDbAlreadyOpen = jError("db has already been open")
 	errorDbNotOpen     = jError("db has not been opened")
@@ -50,8 +60,25 @@ const (
 	errorDecodeFuncOut1NotStringType = jError("decode function first output type not string type")
 	errorDecodeFuncType              = jError("decode function input params must be (string), output params must be (string, error)")
 
+	errorMapNotPtr    = jError("map target must be a non-nil pointer, not %T")
+	errorMapColumn    = jError("can not map column %q value type %T to %s")
+	errorMapColumnErr = jError("can not map column %q value type %T to %s: %v")
+
+	errorDuplicateId        = jError("duplicate %s id %q")
+	errorCircularInclude    = jError("circular include %q in %s id %q")
 	errorWrongTypeOfForeach = jError("wrong params type of tags <foreach>, type must be []string or map[string]string")
+	errorWrongTypeOfItems   = jError("wrong params type %T of tags <foreach>, type must be slice, array or map")
+	errorWrongProperty      = jError("can not get property %q of %T")
+	errorUnknownProperty    = jError("property %q of %T is not found")
+	errorWrongDecimal       = jError("wrong decimal %q")
 	errorWrongSql           = jError("wrong %q sql statements")
+	errorWrongIdentifier    = jError("wrong identifier \"%v\" of !{%s}, identifier must be letters, digits and underscores separated by dots")
+	errorIdentifierNotAllow = jError("identifier %q of !{%s} is not in the allow list of tags <identifier>")
+	errorWrongTimeout       = jError("wrong timeout %q of %q id %q")
+	errorWrongParentOfTag   = jError("wrong parent of tags <%s> in <%s> id %q, parent must be <choose>")
+	errorWrongKeysetSize    = jError("wrong keyset size %d, size must be greater than 0")
+	errorKeysetColumnEmpty  = jError("keyset column is empty")
+	errorWrongPaging        = jError("wrong paging page %d size %d, page and size must be greater than 0")
 
 	errorOprValLenZero               = jError("operators %q, the value length is zero")
 	errorOprValLenNot2               = jError("operators %q, the value length not 2")
@@ -64,23 +91,23 @@ const (
 	fileName      = "config.json"
 	totalRecord   = "TOTALRECORD"
 	allowPagingId = "ALLOWPAGINGID"
-	orderById     = "ORDERBYID"
 	Unknown       = -1
 )
 
 var (
-	conf       = jconf.New()
-	subject    = jevent.New()
-	mux        = new(sync.RWMutex)
-	data       *configData
-	pack       *configPack
-	decodeFunc func(string) (string, error)
-	dsMap      map[string]*dataSource
-	selectMap  map[string]*element
-	insertMap  map[string]*element
-	updateMap  map[string]*element
-	deleteMap  map[string]*element
-	otherMap   map[string]*element
+	conf         = jconf.New()
+	subject      = jevent.New()
+	retrySubject = jevent.New()
+	mux          = new(sync.RWMutex)
+	data         *configData
+	pack         *configPack
+	decodeFunc   func(string) (string, error)
+	dsMap        map[string]*dataSource
+	selectMap    map[string]*element
+	insertMap    map[string]*element
+	updateMap    map[string]*element
+	deleteMap    map[string]*element
+	otherMap     map[string]*element
 )
 
 func init() {
@@ -142,6 +169,11 @@ func SubscribeSql(e jevent.Event) jevent.Subscription {
 	return subject.Subscribe(e)
 }
 
+// SubscribeRetry subscribe UseTx retry event, the event argument is RetryEvent
+func SubscribeRetry(e jevent.Event) jevent.Subscription {
+	return retrySubject.Subscribe(e)
+}
+
 // Init initialize
 func Init() error {
 	if err := conf.Load(); err != nil {
@@ -212,7 +244,18 @@ func GetAgent(dsKey ...string) (*Agent, error) {
 				return nil, err
 			}
 		}
-		return &Agent{db: ds.db, t: t, dbName: ds.DbName}, nil
+		var loc *time.Location
+		var unit time.Duration
+		if loc, unit, err = ds.timeOptions(); err != nil {
+			return nil, err
+		}
+		var mode DecimalMode
+		var f func(string) (interface{}, error)
+		if mode, f, err = ParseDecimalMode(ds.DecimalMode); err != nil {
+			return nil, err
+		}
+		return &Agent{db: ds.db, t: t, dbName: ds.DbName, retry: ds.retryPolicy(), loc: loc, timeUnit: unit,
+			decimalMode: mode, decimalFunc: f, nullWrapper: ds.NullWrapper}, nil
 	}
 }
 
@@ -255,30 +298,115 @@ func loadDaoXml() error {
 	if list, err := loadDaoXmlDir(GetDaoPath()); err != nil {
 		return err
 	} else {
-		selectMap = make(map[string]*element)
-		insertMap = make(map[string]*element)
-		updateMap = make(map[string]*element)
-		deleteMap = make(map[string]*element)
-		otherMap = make(map[string]*element)
-		for _, dao := range list {
-			if dao != nil {
-				for _, elem := range dao.nodes {
-					switch elem.tag {
-					case tagSelect:
-						selectMap[elem.id] = elem
-					case tagInsert:
-						insertMap[elem.id] = elem
-					case tagUpdate:
-						updateMap[elem.id] = elem
-					case tagDelete:
-						deleteMap[elem.id] = elem
-					case tagOther:
-						otherMap[elem.id] = elem
-					}
+		return loadDao(list)
+	}
+}
+
+// loadDao sets the statements of the dao elements, the <include> tags are resolved by the <sql> tags of all dao elements
+// the id of the dao element with namespace is namespace.id, a duplicate id of the same tag is an error
+func loadDao(list []*element) error {
+	sqlMap := make(map[string]*element)
+	sm := make(map[string]*element)
+	im := make(map[string]*element)
+	um := make(map[string]*element)
+	dm := make(map[string]*element)
+	om := make(map[string]*element)
+	for _, dao := range list {
+		if dao != nil {
+			for _, elem := range dao.nodes {
+				var m map[string]*element
+				switch elem.tag {
+				case tagSql:
+					m = sqlMap
+				case tagSelect:
+					m = sm
+				case tagInsert:
+					m = im
+				case tagUpdate:
+					m = um
+				case tagDelete:
+					m = dm
+				case tagOther:
+					m = om
+				default:
+					continue
 				}
+				if m[elem.id] != nil {
+					return errorFmt(errorDuplicateId, elem.tag.String(), elem.id)
+				}
+				m[elem.id] = elem
 			}
 		}
 	}
+	for _, elem := range sqlMap {
+		// resolve a copy to check the missing and circular refid of the fragments which are not included
+		if err := resolveIncludes(elem, elem.copyWith(nil), sqlMap, elem.namespace, []string{elem.id}); err != nil {
+			return err
+		}
+	}
+	for _, m := range []map[string]*element{sm, im, um, dm, om} {
+		for _, elem := range m {
+			if err := resolveIncludes(elem, elem, sqlMap, elem.namespace, nil); err != nil {
+				return err
+			}
+		}
+	}
+	mux.Lock()
+	defer func() {
+		mux.Unlock()
+	}()
+	selectMap = sm
+	insertMap = im
+	updateMap = um
+	deleteMap = dm
+	otherMap = om
+	return nil
+}
+
+// resolveIncludes replaces the nodes of each <include> in e by a copy of the nodes of the referenced <sql>,
+// the ${name} in the copy is replaced by the value of <property name value> in <include>,
+// the refid is looked up in the namespace ns first, then as a full id,
+// the owner is the statement for error message, the path is the sql id of the outer includes
+func resolveIncludes(owner, e *element, sqlMap map[string]*element, ns string, path []string) error {
+	for _, node := range e.nodes {
+		if node.tag != tagInclude {
+			if err := resolveIncludes(owner, node, sqlMap, ns, path); err != nil {
+				return err
+			}
+			continue
+		}
+		refid := node.attr["refid"]
+		var frag *element
+		if ns != "" {
+			frag = sqlMap[fmt.Sprint(ns, ".", refid)]
+		}
+		if frag == nil {
+			frag = sqlMap[refid]
+		}
+		if frag == nil {
+			return errorFmt(errorUnknownSqlRefId, refid, owner.tag.String(), owner.id)
+		}
+		ids := append(append(make([]string, 0, len(path)+1), path...), frag.id)
+		for _, id := range path {
+			if id == frag.id {
+				return errorFmt(errorCircularInclude, strings.Join(ids, " -> "), owner.tag.String(), owner.id)
+			}
+		}
+		props := make(map[string]string)
+		for _, p := range node.nodes {
+			if p.tag == tagProperty {
+				props[p.attr["name"]] = p.attr["value"]
+			}
+		}
+		nodes := make([]*element, 0, len(frag.nodes))
+		for _, n := range frag.nodes {
+			nodes = append(nodes, n.copyWith(props))
+		}
+		node.nodes = nodes
+		if err := resolveIncludes(owner, node, sqlMap, frag.namespace, ids); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -371,61 +499,200 @@ func getElement(ops Operations, id string) (*element, error) {
 	return nil, errorStr(errorUnknownOps)
 }
 
-func getPageSql(t Type, sql, obs string, start, end int64) (pageSql, countSql string) {
-	if obs != "" {
-		obs = fmt.Sprint(" ORDER BY ", obs)
-	}
-	switch t {
-	case MySql:
-		pageSql = fmt.Sprint(
-			"SELECT * FROM (SELECT (@i := @i + 1) AS ", allowPagingId, ", TABLE1.* ",
-			"FROM (SELECT *, 1 AS ", orderById, " FROM (", sql, obs,
-			") AS TBS1 ) AS TABLE1, (SELECT @i := 0) TEMP ORDER BY ", orderById, " DESC ) AS TABLE2 ",
-			"WHERE ", allowPagingId, " BETWEEN ", strconv.FormatInt(start, 10), " AND ", strconv.FormatInt(end, 10))
-	case MSSql:
-		obis := ""
-		if obs == "" {
-			obs = fmt.Sprint("ORDER BY ", orderById, " DESC")
-			obis = fmt.Sprint(", 1 AS ", orderById)
-		}
-		pageSql = fmt.Sprint(
-			"SELECT * FROM (SELECT ROW_NUMBER() OVER(", obs, ") AS ", allowPagingId, ", * ",
-			"FROM (SELECT *", obis, " FROM (", sql, ") AS TBS1) AS TABLE1) AS TABLE2 ",
-			"WHERE ", allowPagingId, " BETWEEN ", strconv.FormatInt(start, 10), " AND ", strconv.FormatInt(end, 10))
-	case Oracle:
-		pageSql = fmt.Sprint(
-			"SELECT T3.* FROM (SELECT T2.*, rownum as ", allowPagingId, " ",
-			"FROM (SELECT T1.*, 1 AS ", orderById, " FROM (", sql, obs, ") T1) T2 ORDER BY ", orderById, ") T3 ",
-			"WHERE ", allowPagingId, " BETWEEN ", strconv.FormatInt(start, 10), " AND ", strconv.FormatInt(end, 10))
-	case PostgreSql:
-		obis := ""
-		if obs == "" {
-			obs = fmt.Sprint("ORDER BY ", orderById, " DESC")
-			obis = fmt.Sprint(", 1 AS ", orderById)
-		}
-		pageSql = fmt.Sprint(
-			"SELECT * FROM (SELECT ROW_NUMBER() OVER(", obs, ") AS ", allowPagingId, ", * ",
-			"FROM (SELECT *", obis, " FROM (", sql, ") AS TBS1) AS TABLE1) AS TABLE2 ",
-			"WHERE ", allowPagingId, " BETWEEN ", strconv.FormatInt(start, 10), " AND ", strconv.FormatInt(end, 10))
-	}
-	countSql = fmt.Sprint("SELECT COUNT(1) AS ", totalRecord, " FROM (", sql, ") DATA")
-	return pageSql, countSql
+// getPageSql returns the page query with its args and the count query of sql, the sql has n args
+func getPageSql(t Type, sql, obs string, start, end int64, n int) (pageSql string, pageArgs []interface{}, countSql string) {
+	if d := t.Dialect(); d != nil {
+		pageSql, pageArgs = d.PageSql(sql, obs, start, end, n)
+		return pageSql, pageArgs, d.CountSql(sql)
+	}
+	return "", nil, getCountSql(sql)
+}
+
+// joinArgs returns a new slice of args followed by more
+func joinArgs(args []interface{}, more []interface{}) []interface{} {
+	list := make([]interface{}, 0, len(args)+len(more))
+	list = append(list, args...)
+	return append(list, more...)
+}
+
+// getValuesInsertSql returns the multi-row VALUES insert query of n rows of cols into table
+func getValuesInsertSql(d Dialect, table string, cols []string, n int) string {
+	return fmt.Sprint("INSERT INTO ", table, " (", strings.Join(cols, ", "), ") ", getValuesSql(d, cols, n))
+}
+
+// getValuesSql returns the VALUES of n rows of cols, the placeholders start from Param(0)
+func getValuesSql(d Dialect, cols []string, n int) string {
+	var sb strings.Builder
+	sb.WriteString("VALUES ")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(")
+		for j := range cols {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(d.Param(i*len(cols) + j))
+		}
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+// getOnConflictSql returns the multi-row VALUES insert query with ON CONFLICT DO UPDATE or DO NOTHING
+// DO NOTHING without conflictCols conflicts on any unique key, DO UPDATE needs conflictCols
+func getOnConflictSql(d Dialect, table string, cols, conflictCols, updateCols []string, n int) string {
+	if len(conflictCols) <= 0 && len(updateCols) > 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(getValuesInsertSql(d, table, cols, n))
+	sb.WriteString(" ON CONFLICT")
+	if len(conflictCols) > 0 {
+		sb.WriteString(fmt.Sprint(" (", strings.Join(conflictCols, ", "), ")"))
+	}
+	if len(updateCols) <= 0 {
+		sb.WriteString(" DO NOTHING")
+		return sb.String()
+	}
+	sb.WriteString(" DO UPDATE SET ")
+	for i, col := range updateCols {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(fmt.Sprint(col, " = EXCLUDED.", col))
+	}
+	return sb.String()
+}
+
+// getMergeSql returns the MERGE INTO query, the source rows are the using query
+// the alias is whether the cols are named after the source alias, otherwise the using query names them
+// empty query means conflictCols is empty
+func getMergeSql(table, using string, cols, conflictCols, updateCols []string, alias bool) string {
+	if len(conflictCols) <= 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprint("MERGE INTO ", table, " T1 USING (", using, ") S1"))
+	if alias {
+		sb.WriteString(fmt.Sprint(" (", strings.Join(cols, ", "), ")"))
+	}
+	sb.WriteString(" ON (")
+	for i, col := range conflictCols {
+		if i > 0 {
+			sb.WriteString(" AND ")
+		}
+		sb.WriteString(fmt.Sprint("T1.", col, " = S1.", col))
+	}
+	sb.WriteString(")")
+	if len(updateCols) > 0 {
+		sb.WriteString(" WHEN MATCHED THEN UPDATE SET ")
+		for i, col := range updateCols {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(fmt.Sprint("T1.", col, " = S1.", col))
+		}
+	}
+	sb.WriteString(fmt.Sprint(" WHEN NOT MATCHED THEN INSERT (", strings.Join(cols, ", "), ") VALUES (S1.",
+		strings.Join(cols, ", S1."), ")"))
+	return sb.String()
+}
+
+// pageLimit returns the row count and the skipped row count of the rows from start to end
+func pageLimit(start, end int64) (limit, offset int64) {
+	if offset = start - 1; offset < 0 {
+		offset = 0
+	}
+	if limit = end - offset; limit < 0 {
+		limit = 0
+	}
+	return limit, offset
+}
+
+// getCountSql returns the count query of sql, the ORDER BY at the end of sql is removed
+func getCountSql(sql string) string {
+	return fmt.Sprint("SELECT COUNT(1) AS ", totalRecord, " FROM (", removeOrderBy(sql), ") DATA")
+}
+
+// removeOrderBy removes the ORDER BY clause at the end of sql,
+// the ORDER BY in parentheses or followed by LIMIT, OFFSET, FETCH or FOR is kept
+func removeOrderBy(sql string) string {
+	depth := 0
+	var quote byte
+	idx := -1
+	end := -1
+	upper := strings.ToUpper(sql)
+	for i := 0; i < len(upper); i++ {
+		c := upper[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && isKeywordAt(upper, i, "ORDER"):
+			if j := skipSpace(upper, i+len("ORDER")); j > i+len("ORDER") && isKeywordAt(upper, j, "BY") {
+				idx = i
+				end = -1
+			}
+		case depth == 0 && idx >= 0 && end < 0:
+			for _, k := range []string{"LIMIT", "OFFSET", "FETCH", "FOR"} {
+				if isKeywordAt(upper, i, k) {
+					end = i
+				}
+			}
+		}
+	}
+	if idx < 0 || end >= 0 {
+		return sql
+	}
+	return trim(sql[:idx])
+}
+
+// isKeywordAt reports whether the word at i of s is k
+func isKeywordAt(s string, i int, k string) bool {
+	if !strings.HasPrefix(s[i:], k) || (i > 0 && isWordChar(s[i-1])) {
+		return false
+	}
+	return i+len(k) == len(s) || !isWordChar(s[i+len(k)])
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\r' || s[i] == '\n') {
+		i++
+	}
+	return i
 }
 
 func getExistsSql(t Type, sql string) string {
-	switch t {
-	case MySql:
-		return fmt.Sprint("SELECT CASE WHEN EXISTS(", sql, ") THEN 'Y' ELSE 'N' END AS E")
-	case MSSql:
-		return fmt.Sprint("SELECT CASE WHEN EXISTS(", sql, ") THEN 'Y' ELSE 'N' END AS E")
-	case Oracle:
-		return fmt.Sprint("SELECT CASE WHEN EXISTS(", sql, ") THEN 'Y' ELSE 'N' END AS E FROM DUAL")
-	case PostgreSql:
-		return fmt.Sprint("SELECT CASE WHEN EXISTS(", sql, ") THEN 'Y' ELSE 'N' END AS E")
+	if d := t.Dialect(); d != nil {
+		return d.ExistsSql(sql)
 	}
 	return sql
 }
 
+// getSavepointSql returns the statements to create, roll back to and release the savepoint name
+// the release statement is empty if the db Type releases savepoints only at the end of the transaction
+func getSavepointSql(t Type, name string) (savepoint, rollback, release string, err error) {
+	if d := t.Dialect(); d != nil {
+		if savepoint, rollback, release = d.SavepointSql(name); savepoint != "" {
+			return savepoint, rollback, release, nil
+		}
+	}
+	return "", "", "", errorStr(errorUnknownSqlTypeForSavepoint)
+}
+
 func trim(str string) string {
 	ts := []string{" ", "　", "\r\n", "\r", "\n"}
 	for i := 0; i < len(ts); i++ {
@@ -473,6 +740,19 @@ func removeComment(str string) string {
 	return str
 }
 
+// parseTimeout parses the statement timeout attribute
+// an integer is seconds, otherwise it must be a time.ParseDuration string, e.g. 500ms, 1m30s
+func parseTimeout(str string) (time.Duration, error) {
+	str = trim(str)
+	if str == "" {
+		return 0, nil
+	}
+	if sec, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return time.Duration(sec) * time.Second, nil
+	}
+	return time.ParseDuration(str)
+}
+
 func toElement(path string) (dao *element, err error) {
 	var file *os.File
 	if file, err = os.Open(path); err != nil {
@@ -497,7 +777,11 @@ func toElement(path string) (dao *element, err error) {
 			switch tn := parseTag(name); tn {
 			case tagDao:
 				if dao == nil {
-					dao = &element{id: "", tag: tn, nodes: make([]*element, 0)}
+					dao = &element{id: "", tag: tn, attr: make(map[string]string), nodes: make([]*element, 0)}
+					for _, a := range t.Attr {
+						dao.attr[a.Name.Local] = a.Value
+					}
+					dao.namespace = trim(dao.attr["namespace"])
 				}
 			case tagSelect:
 				fallthrough
@@ -508,12 +792,22 @@ func toElement(path string) (dao *element, err error) {
 			case tagDelete:
 				fallthrough
 			case tagOther:
+				fallthrough
+			case tagSql:
 				if dao != nil && len(idx) <= 0 {
 					attr := make(map[string]string)
 					for _, a := range t.Attr {
 						attr[a.Name.Local] = a.Value
 					}
-					dao.nodes = append(dao.nodes, &element{id: attr["id"], tag: tn, attr: attr, text: "", nodes: make([]*element, 0)})
+					var timeout time.Duration
+					if timeout, err = parseTimeout(attr["timeout"]); err != nil {
+						return nil, errorFmt(errorWrongTimeout, attr["timeout"], tn.String(), attr["id"])
+					}
+					id := attr["id"]
+					if dao.namespace != "" {
+						id = fmt.Sprint(dao.namespace, ".", id)
+					}
+					dao.nodes = append(dao.nodes, &element{id: id, namespace: dao.namespace, tag: tn, attr: attr, text: "", timeout: timeout, nodes: make([]*element, 0)})
 					idx = append(idx, len(dao.nodes)-1)
 				}
 			case tagIf:
@@ -522,12 +816,33 @@ func toElement(path string) (dao *element, err error) {
 				fallthrough
 			case tagWhere:
 				fallthrough
+			case tagSet:
+				fallthrough
+			case tagTrim:
+				fallthrough
 			case tagOrderBy:
+				fallthrough
+			case tagCount:
+				fallthrough
+			case tagChoose:
+				fallthrough
+			case tagWhen:
+				fallthrough
+			case tagOtherwise:
+				fallthrough
+			case tagInclude:
+				fallthrough
+			case tagProperty:
+				fallthrough
+			case tagIdentifier:
 				if dao != nil && len(idx) > 0 {
 					e := dao
 					for _, i := range idx {
 						e = e.nodes[i]
 					}
+					if (tn == tagWhen || tn == tagOtherwise) && e.tag != tagChoose {
+						return nil, errorFmt(errorWrongParentOfTag, tn.String(), e.tag.String(), dao.nodes[idx[0]].id)
+					}
 					attr := make(map[string]string)
 					for _, a := range t.Attr {
 						attr[strings.ToLower(a.Name.Local)] = a.Value
@@ -5,6 +5,7 @@
 package jsql
 
 import (
+	"database/sql/driver"
 	"fmt"
 	//_ "github.com/denisenkom/go-mssqldb"
 	//_ "github.com/go-sql-driver/mysql"
@@ -913,3 +914,118 @@ func testDropTable(t *testing.T) {
 		}
 	}
 }
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		in  string
+		out time.Duration
+		err bool
+	}{
+		{"", 0, false},
+		{"30", 30 * time.Second, false},
+		{" 500ms ", 500 * time.Millisecond, false},
+		{"1m30s", 90 * time.Second, false},
+		{"abc", 0, true},
+	}
+	for _, v := range tests {
+		d, err := parseTimeout(v.in)
+		if v.err {
+			assert.NotNil(t, err, fmt.Sprintf("%v must be return error", v.in))
+		} else {
+			assert.Nil(t, err)
+			assert.Equal(t, v.out, d, fmt.Sprintf("%v != %v", d, v.out))
+		}
+	}
+}
+
+func TestGetSavepointSql(t *testing.T) {
+	tests := []struct {
+		in       Type
+		sp       string
+		rollback string
+		release  string
+		err      bool
+	}{
+		{MySql, "SAVEPOINT SP1", "ROLLBACK TO SAVEPOINT SP1", "RELEASE SAVEPOINT SP1", false},
+		{MSSql, "SAVE TRANSACTION SP1", "ROLLBACK TRANSACTION SP1", "", false},
+		{Oracle, "SAVEPOINT SP1", "ROLLBACK TO SAVEPOINT SP1", "", false},
+		{PostgreSql, "SAVEPOINT SP1", "ROLLBACK TO SAVEPOINT SP1", "RELEASE SAVEPOINT SP1", false},
+		{Sqlite, "SAVEPOINT SP1", "ROLLBACK TO SAVEPOINT SP1", "RELEASE SAVEPOINT SP1", false},
+		{Unknown, "", "", "", true},
+	}
+	for _, v := range tests {
+		sp, rollback, release, err := getSavepointSql(v.in, "SP1")
+		if v.err {
+			assert.NotNil(t, err, fmt.Sprintf("%v must be return error", v.in))
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, v.sp, sp, fmt.Sprintf("%v != %v", sp, v.sp))
+		assert.Equal(t, v.rollback, rollback, fmt.Sprintf("%v != %v", rollback, v.rollback))
+		assert.Equal(t, v.release, release, fmt.Sprintf("%v != %v", release, v.release))
+	}
+}
+
+func TestAgent_QueryPageWithSql(t *testing.T) {
+	pageQuery := "SELECT * FROM (SELECT * FROM T2 WHERE ID > $1) AS TBS1 ORDER BY ID LIMIT $2 OFFSET $3"
+	countQuery := "SELECT COUNT(1) AS TOTALRECORD FROM (SELECT * FROM T2 WHERE ID > $1) DATA"
+	testSetQuery(pageQuery, []testColumn{{name: "id", dbType: "INT8"}}, []driver.Value{int64(6)})
+	testSetQuery(countQuery, []testColumn{{name: "totalrecord", dbType: "INT8"}}, []driver.Value{int64(11)})
+	a := &Agent{db: testOpenDB(), t: PostgreSql}
+	res, err := a.QueryPageWithSql("SELECT * FROM T2 WHERE ID > $1", "ID", 6, 10, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(11), res.TotalRecord())
+	assert.Equal(t, []map[string]interface{}{{"id": int64(6)}}, res.Rows())
+	assert.Equal(t, []driver.Value{int64(0), int64(5), int64(5)}, testTakeArgs(pageQuery))
+	assert.Equal(t, []driver.Value{int64(0)}, testTakeArgs(countQuery))
+}
+
+func TestRemoveOrderBy(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"SELECT * FROM T", "SELECT * FROM T"},
+		{"SELECT * FROM T ORDER BY ID DESC", "SELECT * FROM T"},
+		{"SELECT * FROM T order\n by ID, NAME", "SELECT * FROM T"},
+		{"SELECT ROW_NUMBER() OVER(ORDER BY ID) AS N FROM T", "SELECT ROW_NUMBER() OVER(ORDER BY ID) AS N FROM T"},
+		{"SELECT * FROM (SELECT * FROM T ORDER BY ID) A ORDER BY NAME", "SELECT * FROM (SELECT * FROM T ORDER BY ID) A"},
+		{"SELECT * FROM T WHERE A = 'ORDER BY' ORDER BY ID", "SELECT * FROM T WHERE A = 'ORDER BY'"},
+		{"SELECT * FROM T ORDER BY ID LIMIT 10", "SELECT * FROM T ORDER BY ID LIMIT 10"},
+		{"SELECT * FROM T ORDER BY ID FOR UPDATE", "SELECT * FROM T ORDER BY ID FOR UPDATE"},
+		{"SELECT BORDER_BY FROM T", "SELECT BORDER_BY FROM T"},
+	}
+	for _, v := range tests {
+		out := removeOrderBy(v.in)
+		assert.Equal(t, v.out, out, fmt.Sprintf("%v != %v", out, v.out))
+	}
+}
+
+func TestGetPageSql(t *testing.T) {
+	tests := []struct {
+		t     Type
+		obs   string
+		start int64
+		end   int64
+		out   string
+		args  []interface{}
+	}{
+		{MySql, "", 1, 10, "SELECT * FROM (SELECT * FROM T) AS TBS1 LIMIT ? OFFSET ?", []interface{}{int64(10), int64(0)}},
+		{MySql, "ID DESC", 6, 10, "SELECT * FROM (SELECT * FROM T) AS TBS1 ORDER BY ID DESC LIMIT ? OFFSET ?", []interface{}{int64(5), int64(5)}},
+		{MSSql, "", 1, 10, "SELECT * FROM (SELECT * FROM T) AS TBS1 ORDER BY (SELECT NULL) OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY", []interface{}{int64(0), int64(10)}},
+		{MSSql, "ID", 6, 10, "SELECT * FROM (SELECT * FROM T) AS TBS1 ORDER BY ID OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY", []interface{}{int64(5), int64(5)}},
+		{Oracle, "", 6, 10, "SELECT T2.* FROM (SELECT T1.*, ROW_NUMBER() OVER(ORDER BY NULL) AS ALLOWPAGINGID FROM (SELECT * FROM T) T1) T2 WHERE ALLOWPAGINGID BETWEEN :1 AND :2 ORDER BY ALLOWPAGINGID", []interface{}{int64(6), int64(10)}},
+		{PostgreSql, "ID", 6, 10, "SELECT * FROM (SELECT * FROM T) AS TBS1 ORDER BY ID LIMIT $2 OFFSET $3", []interface{}{int64(5), int64(5)}},
+		{Sqlite, "", 1, 10, "SELECT * FROM (SELECT * FROM T) AS TBS1 LIMIT ? OFFSET ?", []interface{}{int64(10), int64(0)}},
+		{Sqlite, "ID DESC", 6, 10, "SELECT * FROM (SELECT * FROM T) AS TBS1 ORDER BY ID DESC LIMIT ? OFFSET ?", []interface{}{int64(5), int64(5)}},
+		{Sqlite, "", 0, -1, "SELECT * FROM (SELECT * FROM T) AS TBS1 LIMIT ? OFFSET ?", []interface{}{int64(0), int64(0)}},
+	}
+	for _, v := range tests {
+		pageSql, pageArgs, countSql := getPageSql(v.t, "SELECT * FROM T", v.obs, v.start, v.end, 1)
+		assert.Equal(t, v.out, pageSql, fmt.Sprintf("%v != %v", pageSql, v.out))
+		assert.Equal(t, v.args, pageArgs)
+		assert.Equal(t, "SELECT COUNT(1) AS TOTALRECORD FROM (SELECT * FROM T) DATA", countSql)
+	}
+	existsSql := getExistsSql(Sqlite, "SELECT * FROM T")
+	assert.Equal(t, "SELECT CASE WHEN EXISTS(SELECT * FROM T) THEN 'Y' ELSE 'N' END AS E", existsSql)
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import "fmt"
+
+// Keyset is the cursor of keyset (seek) pagination, it is used by QueryKeyset
+// the rows are sorted by Column, the rows after Last are queried instead of skipping the rows before start,
+// so the query cost does not grow with the page number and no count query is executed
+type Keyset struct {
+	// Column is the cursor column, its values must be unique and sortable, e.g. the primary key
+	Column string
+	// Last is the Column value of the last row of previous page, nil queries the first page
+	Last interface{}
+	// Size is the max row count of the page
+	Size int64
+	// Desc sorts the rows by Column in descending order
+	Desc bool
+}
+
+// Next returns the Keyset of next page, the last is the Column value of the last row of current page
+func (k Keyset) Next(last interface{}) Keyset {
+	k.Last = last
+	return k
+}
+
+// getKeysetSql returns the query and args of the page after k.Last of sql, the sql has args
+func getKeysetSql(t Type, sql string, args []interface{}, k Keyset) (string, []interface{}, error) {
+	if k.Column == "" {
+		return "", nil, errorStr(errorKeysetColumnEmpty)
+	}
+	if k.Size <= 0 {
+		return "", nil, errorFmt(errorWrongKeysetSize, k.Size)
+	}
+	where := ""
+	obs := k.Column
+	if k.Last != nil {
+		opr := " > "
+		if k.Desc {
+			opr = " < "
+		}
+		where = fmt.Sprint(" WHERE ", k.Column, opr, t.Param(len(args)))
+		args = joinArgs(args, []interface{}{k.Last})
+	}
+	if k.Desc {
+		obs = fmt.Sprint(obs, " DESC")
+	}
+	pageSql, pageArgs, _ := getPageSql(t, fmt.Sprint("SELECT * FROM (", sql, ") TBK", where), obs, 1, k.Size, len(args))
+	return pageSql, joinArgs(args, pageArgs), nil
+}
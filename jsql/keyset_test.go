@@ -0,0 +1,54 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func TestGetKeysetSql(t *testing.T) {
+	tests := []struct {
+		t    Type
+		k    Keyset
+		out  string
+		args []interface{}
+		err  bool
+	}{
+		{MySql, Keyset{Column: "ID", Size: 5}, "SELECT * FROM (SELECT * FROM (SELECT * FROM T WHERE A = ?) TBK) AS TBS1 ORDER BY ID LIMIT ? OFFSET ?",
+			[]interface{}{"a", int64(5), int64(0)}, false},
+		{MySql, Keyset{Column: "ID", Last: 10, Size: 5, Desc: true}, "SELECT * FROM (SELECT * FROM (SELECT * FROM T WHERE A = ?) TBK WHERE ID < ?) AS TBS1 ORDER BY ID DESC LIMIT ? OFFSET ?",
+			[]interface{}{"a", 10, int64(5), int64(0)}, false},
+		{PostgreSql, Keyset{Column: "ID", Last: 10, Size: 5}, "SELECT * FROM (SELECT * FROM (SELECT * FROM T WHERE A = ?) TBK WHERE ID > $2) AS TBS1 ORDER BY ID LIMIT $3 OFFSET $4",
+			[]interface{}{"a", 10, int64(5), int64(0)}, false},
+		{MySql, Keyset{Size: 5}, "", nil, true},
+		{MySql, Keyset{Column: "ID"}, "", nil, true},
+	}
+	for _, v := range tests {
+		query, args, err := getKeysetSql(v.t, "SELECT * FROM T WHERE A = ?", []interface{}{"a"}, v.k)
+		if v.err {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, v.out, query)
+		assert.Equal(t, v.args, args)
+	}
+	k := Keyset{Column: "ID", Size: 5}.Next(10)
+	assert.Equal(t, 10, k.Last)
+}
+
+func TestAgent_QueryKeyset(t *testing.T) {
+	cols := []testColumn{{name: "ID", dbType: "BIGINT", scanType: reflect.TypeOf(int64(0))}}
+	query := "SELECT * FROM (SELECT * FROM (SELECT ID FROM T) TBK WHERE ID > ?) AS TBS1 ORDER BY ID LIMIT ? OFFSET ?"
+	testSetQuery(query, cols, []driver.Value{int64(3)}, []driver.Value{int64(4)})
+	a := &Agent{db: testOpenDB(), t: MySql}
+	res, err := a.QueryKeysetWithSql("SELECT ID FROM T", Keyset{Column: "ID", Last: int64(2), Size: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]interface{}{{"ID": int64(3)}, {"ID": int64(4)}}, res.Rows())
+	assert.Equal(t, []driver.Value{int64(2), int64(2), int64(0)}, testTakeArgs(query))
+}
@@ -0,0 +1,269 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"github.com/xjustloveux/jgo/jcast"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tagName is the struct tag key of column name, e.g. `jsql:"COL_NAME"`
+// `jsql:"-"` ignores the field, if the field has no jsql tag, the json tag name or field name is used
+const tagName = "jsql"
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+	fieldsCache sync.Map
+)
+
+// mapTo sets src into v without json encoding, the v must be a non-nil pointer
+// a map[string]interface{} src is mapped to the struct fields by column name,
+// a []map[string]interface{} src is mapped to a slice element by element
+func mapTo(src interface{}, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errorFmt(errorMapNotPtr, v)
+	}
+	return setValue(rv.Elem(), src, "")
+}
+
+// mapRows sets the rows of result into v
+// a slice point receives the rows, other point receives Rows and RowStart, RowEnd, TotalRecord, PageInfo if page is true
+func mapRows(result Result, page bool, v interface{}) error {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Slice {
+		return mapTo(result.Rows(), v)
+	}
+	m := map[string]interface{}{"Rows": result.Rows()}
+	if page {
+		m["RowStart"] = result.RowStart()
+		m["RowEnd"] = result.RowEnd()
+		m["TotalRecord"] = result.TotalRecord()
+		if pr, ok := result.(PagingResult); ok {
+			m["PageInfo"] = pr.PageInfo()
+		}
+	}
+	return mapTo(m, v)
+}
+
+// mapRow sets the first row of result into v
+func mapRow(result Result, v interface{}) error {
+	m := result.Row()
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	return mapTo(m, v)
+}
+
+// structFields returns the field index of each lower case column name of struct type t
+func structFields(t reflect.Type) map[string][]int {
+	if f, ok := fieldsCache.Load(t); ok {
+		return f.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	addStructFields(fields, t, nil)
+	f, _ := fieldsCache.LoadOrStore(t, fields)
+	return f.(map[string][]int)
+}
+
+func addStructFields(fields map[string][]int, t reflect.Type, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup(tagName)
+		if tag == "-" {
+			continue
+		}
+		idx := make([]int, len(index)+1)
+		copy(idx, index)
+		idx[len(index)] = i
+		if sf.Anonymous && !hasTag {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !reflect.PointerTo(ft).Implements(scannerType) && ft != timeType {
+				if sf.Type.Kind() == reflect.Ptr && !sf.IsExported() {
+					continue
+				}
+				addStructFields(fields, ft, idx)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			if js, ok := sf.Tag.Lookup("json"); ok {
+				if name = strings.Split(js, ",")[0]; name == "-" {
+					continue
+				}
+			}
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		key := strings.ToLower(name)
+		if old, ok := fields[key]; ok && len(old) <= len(idx) {
+			// the shallower field wins, same as embedded field promotion
+			continue
+		}
+		fields[key] = idx
+	}
+}
+
+// fieldByIndex returns the nested field of v, nil embedded pointers are allocated
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+func setStruct(v reflect.Value, m map[string]interface{}) error {
+	fields := structFields(v.Type())
+	for col, val := range m {
+		if index, ok := fields[strings.ToLower(col)]; ok {
+			if err := setValue(fieldByIndex(v, index), val, col); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setValue sets val into v, the col is used for error message
+func setValue(v reflect.Value, val interface{}, col string) error {
+	if val != nil && reflect.TypeOf(val).AssignableTo(v.Type()) {
+		v.Set(reflect.ValueOf(val))
+		return nil
+	}
+	if vr, ok := val.(driver.Valuer); ok {
+		// e.g. the sql.Null* of the NullWrapper of the DataSource
+		var err error
+		if val, err = vr.Value(); err != nil {
+			return errorFmt(errorMapColumnErr, col, vr, v.Type(), err)
+		}
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(scannerType) {
+		return v.Addr().Interface().(sql.Scanner).Scan(val)
+	}
+	if val == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		nv := reflect.New(v.Type().Elem())
+		if err := setValue(nv.Elem(), val, col); err != nil {
+			return err
+		}
+		v.Set(nv)
+		return nil
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Type().AssignableTo(v.Type()) {
+		v.Set(rv)
+		return nil
+	}
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		if t, ok := val.(time.Time); ok {
+			v.SetString(t.Format(time.RFC3339Nano))
+		} else {
+			v.SetString(jcast.String(val))
+		}
+		return nil
+	case reflect.Bool:
+		var b bool
+		if b, err = jcast.Bool(val); err == nil {
+			v.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = jcast.Int64(val); err == nil && !v.OverflowInt(i) {
+			v.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		if u, err = jcast.Uint64(val); err == nil && !v.OverflowUint(u) {
+			v.SetUint(u)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if r, ok := val.(*big.Rat); ok {
+			f, _ = r.Float64()
+		} else {
+			f, err = jcast.Float64(val)
+		}
+		if err == nil && !v.OverflowFloat(f) {
+			v.SetFloat(f)
+			return nil
+		}
+	case reflect.Struct:
+		if v.Type() == timeType {
+			var t time.Time
+			if t, err = jcast.Time(val); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
+			}
+		} else if m, ok := val.(map[string]interface{}); ok {
+			return setStruct(v, m)
+		}
+	case reflect.Slice:
+		if rv.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+			s := reflect.MakeSlice(v.Type(), rv.Len(), rv.Len())
+			for i := 0; i < rv.Len(); i++ {
+				if err = setValue(s.Index(i), rv.Index(i).Interface(), col); err != nil {
+					return err
+				}
+			}
+			v.Set(s)
+			return nil
+		}
+		if s, ok := val.(string); ok && v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(s))
+			return nil
+		}
+	case reflect.Map:
+		if m, ok := val.(map[string]interface{}); ok && v.Type().Key().Kind() == reflect.String {
+			nm := reflect.MakeMapWithSize(v.Type(), len(m))
+			for mk, mv := range m {
+				ev := reflect.New(v.Type().Elem()).Elem()
+				if err = setValue(ev, mv, col); err != nil {
+					return err
+				}
+				nm.SetMapIndex(reflect.ValueOf(mk).Convert(v.Type().Key()), ev)
+			}
+			v.Set(nm)
+			return nil
+		}
+	}
+	if b, ok := val.([]byte); ok && v.CanAddr() && json.Valid(b) {
+		if err = json.Unmarshal(b, v.Addr().Interface()); err == nil {
+			return nil
+		}
+	}
+	if err != nil {
+		return errorFmt(errorMapColumnErr, col, val, v.Type().String(), err)
+	}
+	return errorFmt(errorMapColumn, col, val, v.Type().String())
+}
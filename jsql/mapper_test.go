@@ -0,0 +1,117 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/xjustloveux/jgo/jcast"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testUpper string
+
+func (u *testUpper) Scan(src interface{}) error {
+	*u = testUpper(strings.ToUpper(jcast.String(src)))
+	return nil
+}
+
+type testBase struct {
+	ID      int64 `jsql:"ID"`
+	Created time.Time
+}
+
+type TestExtra struct {
+	Extra string `jsql:"EXTRA"`
+}
+
+type testEntity struct {
+	testBase
+	*TestExtra
+	Name     string         `jsql:"USER_NAME"`
+	Nick     *string        `jsql:"NICK"`
+	Age      *int           `jsql:"AGE"`
+	Code     testUpper      `jsql:"CODE"`
+	Note     sql.NullString `jsql:"NOTE"`
+	JsonName string         `json:"JSON_NAME"`
+	Ignored  string         `jsql:"-"`
+	Tags     []string       `jsql:"TAGS"`
+}
+
+func TestMapTo(t *testing.T) {
+	created := time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC)
+	m := map[string]interface{}{
+		"ID":        int64(9007199254740993),
+		"CREATED":   created,
+		"EXTRA":     "extra",
+		"user_name": "jaja",
+		"NICK":      nil,
+		"AGE":       int64(18),
+		"CODE":      []byte("abc"),
+		"NOTE":      "note",
+		"JSON_NAME": "json",
+		"Ignored":   "ignored",
+		"TAGS":      []interface{}{"a", "b"},
+	}
+	var e testEntity
+	if !assert.Nil(t, mapTo(m, &e)) {
+		return
+	}
+	assert.Equal(t, int64(9007199254740993), e.ID)
+	assert.Equal(t, created, e.Created)
+	if assert.NotNil(t, e.TestExtra) {
+		assert.Equal(t, "extra", e.Extra)
+	}
+	assert.Equal(t, "jaja", e.Name)
+	assert.Nil(t, e.Nick)
+	if assert.NotNil(t, e.Age) {
+		assert.Equal(t, 18, *e.Age)
+	}
+	assert.Equal(t, testUpper("ABC"), e.Code)
+	assert.Equal(t, sql.NullString{String: "note", Valid: true}, e.Note)
+	assert.Equal(t, "json", e.JsonName)
+	assert.Equal(t, "", e.Ignored)
+	assert.Equal(t, []string{"a", "b"}, e.Tags)
+
+	assert.NotNil(t, mapTo(m, e))
+	assert.NotNil(t, mapTo(map[string]interface{}{"AGE": "abc"}, &e))
+	assert.NotNil(t, mapTo(map[string]interface{}{"AGE": int64(1 << 40)}, &struct{ AGE int8 }{}))
+}
+
+func TestMapRows(t *testing.T) {
+	type data struct {
+		ID   int64
+		NAME string
+	}
+	r := agentResult{
+		rows: []map[string]interface{}{
+			{"ID": int64(1), "NAME": "A"},
+			{"ID": int64(2), "NAME": nil},
+		},
+		rowStart:    1,
+		rowEnd:      2,
+		totalRecord: 10,
+	}
+	var list []data
+	assert.Nil(t, mapRows(r, false, &list))
+	assert.Equal(t, []data{{ID: 1, NAME: "A"}, {ID: 2}}, list)
+	var page struct {
+		Rows        []data
+		RowStart    int64
+		RowEnd      int64
+		TotalRecord int64
+	}
+	assert.Nil(t, mapRows(r, true, &page))
+	assert.Equal(t, list, page.Rows)
+	assert.Equal(t, int64(1), page.RowStart)
+	assert.Equal(t, int64(2), page.RowEnd)
+	assert.Equal(t, int64(10), page.TotalRecord)
+	var row data
+	assert.Nil(t, mapRow(r, &row))
+	assert.Equal(t, data{ID: 1, NAME: "A"}, row)
+	assert.Nil(t, mapRow(agentResult{}, &row))
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type msSqlDialect struct{}
+
+func (msSqlDialect) DriverName() string {
+	return "sqlserver"
+}
+
+func (msSqlDialect) Param(i int) string {
+	return fmt.Sprint("@p", strconv.FormatInt(int64(i+1), 10))
+}
+
+func (msSqlDialect) QuoteIdentifier(name string) string {
+	return fmt.Sprint("[", strings.ReplaceAll(name, "]", "]]"), "]")
+}
+
+// PageSql uses OFFSET FETCH, which needs ORDER BY, so the rows are not sorted if obs is empty
+func (d msSqlDialect) PageSql(sql, obs string, start, end int64, n int) (string, []interface{}) {
+	if obs == "" {
+		obs = "(SELECT NULL)"
+	}
+	limit, offset := pageLimit(start, end)
+	return fmt.Sprint("SELECT * FROM (", sql, ") AS TBS1 ORDER BY ", obs,
+			" OFFSET ", d.Param(n), " ROWS FETCH NEXT ", d.Param(n+1), " ROWS ONLY"),
+		[]interface{}{offset, limit}
+}
+
+func (msSqlDialect) CountSql(sql string) string {
+	return getCountSql(sql)
+}
+
+func (msSqlDialect) ExistsSql(sql string) string {
+	return fmt.Sprint("SELECT CASE WHEN EXISTS(", sql, ") THEN 'Y' ELSE 'N' END AS E")
+}
+
+func (msSqlDialect) TablesSql(string) (string, []interface{}) {
+	return sqlQueryTablesMSSql, nil
+}
+
+func (msSqlDialect) TableSchemaSql(_, table string) (string, []interface{}) {
+	return sqlQueryTableSchemaMSSql, []interface{}{table}
+}
+
+// MaxParams is 2100 minus @stmt and @params of sp_executesql, which go-mssqldb sends the query by
+func (msSqlDialect) MaxParams() int {
+	return 2098
+}
+
+// MaxRows is the max number of the row value expressions of INSERT VALUES
+func (msSqlDialect) MaxRows() int {
+	return 1000
+}
+
+func (d msSqlDialect) BatchInsertSql(table string, cols []string, n int) string {
+	return getValuesInsertSql(d, table, cols, n)
+}
+
+// UpsertSql uses MERGE INTO with the rows of VALUES, MERGE must be terminated by a semicolon
+func (d msSqlDialect) UpsertSql(table string, cols, conflictCols, updateCols []string, n int) string {
+	if query := getMergeSql(table, getValuesSql(d, cols, n), cols, conflictCols, updateCols, true); query != "" {
+		return fmt.Sprint(query, ";")
+	}
+	return ""
+}
+
+func (msSqlDialect) LastInsertIdSql(insertSql string) string {
+	return fmt.Sprint(insertSql, "; SELECT SCOPE_IDENTITY()")
+}
+
+func (msSqlDialect) SavepointSql(name string) (savepoint, rollback, release string) {
+	return fmt.Sprint("SAVE TRANSACTION ", name), fmt.Sprint("ROLLBACK TRANSACTION ", name), ""
+}
+
+// IsRetryable reports whether err is the deadlock victim error 1205
+func (msSqlDialect) IsRetryable(err error) bool {
+	return matchError(err, func(e error) bool {
+		n, ok := errorNumber(e)
+		return ok && n == 1205
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type mySqlDialect struct{}
+
+func (mySqlDialect) DriverName() string {
+	return "mysql"
+}
+
+func (mySqlDialect) Param(int) string {
+	return "?"
+}
+
+func (mySqlDialect) QuoteIdentifier(name string) string {
+	return fmt.Sprint("`", strings.ReplaceAll(name, "`", "``"), "`")
+}
+
+func (mySqlDialect) PageSql(sql, obs string, start, end int64, _ int) (string, []interface{}) {
+	if obs != "" {
+		obs = fmt.Sprint(" ORDER BY ", obs)
+	}
+	limit, offset := pageLimit(start, end)
+	return fmt.Sprint("SELECT * FROM (", sql, ") AS TBS1", obs, " LIMIT ? OFFSET ?"), []interface{}{limit, offset}
+}
+
+func (mySqlDialect) CountSql(sql string) string {
+	return getCountSql(sql)
+}
+
+func (mySqlDialect) ExistsSql(sql string) string {
+	return fmt.Sprint("SELECT CASE WHEN EXISTS(", sql, ") THEN 'Y' ELSE 'N' END AS E")
+}
+
+func (mySqlDialect) TablesSql(dbName string) (string, []interface{}) {
+	return sqlQueryTablesMySql, []interface{}{dbName}
+}
+
+func (mySqlDialect) TableSchemaSql(dbName, table string) (string, []interface{}) {
+	return sqlQueryTableSchemaMySql, []interface{}{dbName, table}
+}
+
+func (mySqlDialect) MaxParams() int {
+	return 65535
+}
+
+func (mySqlDialect) MaxRows() int {
+	return 0
+}
+
+func (d mySqlDialect) BatchInsertSql(table string, cols []string, n int) string {
+	return getValuesInsertSql(d, table, cols, n)
+}
+
+// UpsertSql uses ON DUPLICATE KEY UPDATE with VALUES(col) which works on MySQL 5.7, 8.x and MariaDB,
+// it is deprecated since MySQL 8.0.20 but still supported, the row alias would need 8.0.19 and is not in MariaDB,
+// the conflictCols are not used because any unique key conflicts, the update of the first column to itself does nothing
+func (d mySqlDialect) UpsertSql(table string, cols, _, updateCols []string, n int) string {
+	var sb strings.Builder
+	sb.WriteString(getValuesInsertSql(d, table, cols, n))
+	if len(updateCols) <= 0 {
+		sb.WriteString(fmt.Sprint(" ON DUPLICATE KEY UPDATE ", cols[0], " = ", cols[0]))
+		return sb.String()
+	}
+	sb.WriteString(" ON DUPLICATE KEY UPDATE ")
+	for i, col := range updateCols {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(fmt.Sprint(col, " = VALUES(", col, ")"))
+	}
+	return sb.String()
+}
+
+func (mySqlDialect) LastInsertIdSql(insertSql string) string {
+	return insertSql
+}
+
+func (mySqlDialect) SavepointSql(name string) (savepoint, rollback, release string) {
+	return fmt.Sprint("SAVEPOINT ", name), fmt.Sprint("ROLLBACK TO SAVEPOINT ", name), fmt.Sprint("RELEASE SAVEPOINT ", name)
+}
+
+// IsRetryable reports whether err is the deadlock error 1213
+func (mySqlDialect) IsRetryable(err error) bool {
+	return matchError(err, func(e error) bool {
+		n, ok := errorNumber(e)
+		return ok && n == 1213
+	})
+}
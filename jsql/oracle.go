@@ -0,0 +1,116 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type oracleDialect struct{}
+
+func (oracleDialect) DriverName() string {
+	return "godror"
+}
+
+func (oracleDialect) Param(i int) string {
+	return fmt.Sprint(":", strconv.FormatInt(int64(i), 10))
+}
+
+func (oracleDialect) QuoteIdentifier(name string) string {
+	return fmt.Sprint(`"`, strings.ReplaceAll(name, `"`, `""`), `"`)
+}
+
+// PageSql uses ROW_NUMBER, which works before Oracle 12c
+func (d oracleDialect) PageSql(sql, obs string, start, end int64, n int) (string, []interface{}) {
+	if obs == "" {
+		obs = "NULL"
+	}
+	return fmt.Sprint("SELECT T2.* FROM (SELECT T1.*, ROW_NUMBER() OVER(ORDER BY ", obs, ") AS ", allowPagingId,
+			" FROM (", sql, ") T1) T2 WHERE ", allowPagingId, " BETWEEN ", d.Param(n), " AND ", d.Param(n+1),
+			" ORDER BY ", allowPagingId),
+		[]interface{}{start, end}
+}
+
+func (oracleDialect) CountSql(sql string) string {
+	return getCountSql(sql)
+}
+
+func (oracleDialect) ExistsSql(sql string) string {
+	return fmt.Sprint("SELECT CASE WHEN EXISTS(", sql, ") THEN 'Y' ELSE 'N' END AS E FROM DUAL")
+}
+
+func (oracleDialect) TablesSql(string) (string, []interface{}) {
+	return sqlQueryTablesOracle, nil
+}
+
+func (oracleDialect) TableSchemaSql(dbName, table string) (string, []interface{}) {
+	return sqlQueryTableSchemaOracle, []interface{}{dbName, table}
+}
+
+func (oracleDialect) MaxParams() int {
+	return 65535
+}
+
+func (oracleDialect) MaxRows() int {
+	return 0
+}
+
+// BatchInsertSql uses INSERT ALL, which works before Oracle 23c
+func (d oracleDialect) BatchInsertSql(table string, cols []string, n int) string {
+	var sb strings.Builder
+	sb.WriteString("INSERT ALL")
+	for i := 0; i < n; i++ {
+		sb.WriteString(fmt.Sprint(" INTO ", table, " (", strings.Join(cols, ", "), ") VALUES ("))
+		for j := range cols {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(d.Param(i*len(cols) + j))
+		}
+		sb.WriteString(")")
+	}
+	sb.WriteString(" SELECT 1 FROM DUAL")
+	return sb.String()
+}
+
+// UpsertSql uses MERGE INTO with the rows of SELECT FROM DUAL
+func (d oracleDialect) UpsertSql(table string, cols, conflictCols, updateCols []string, n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(" UNION ALL ")
+		}
+		sb.WriteString("SELECT ")
+		for j, col := range cols {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(fmt.Sprint(d.Param(i*len(cols)+j), " AS ", col))
+		}
+		sb.WriteString(" FROM DUAL")
+	}
+	return getMergeSql(table, sb.String(), cols, conflictCols, updateCols, false)
+}
+
+func (oracleDialect) LastInsertIdSql(insertSql string) string {
+	return insertSql
+}
+
+func (oracleDialect) SavepointSql(name string) (savepoint, rollback, release string) {
+	return fmt.Sprint("SAVEPOINT ", name), fmt.Sprint("ROLLBACK TO SAVEPOINT ", name), ""
+}
+
+// IsRetryable reports whether err is ORA-00060 deadlock or ORA-08177 serialization failure
+func (oracleDialect) IsRetryable(err error) bool {
+	return matchError(err, func(e error) bool {
+		if n, ok := errorNumber(e); ok && (n == 60 || n == 8177) {
+			return true
+		}
+		msg := e.Error()
+		return strings.Contains(msg, "ORA-00060") || strings.Contains(msg, "ORA-08177")
+	})
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+// Paging is the page number and page size of QueryPaging
+type Paging struct {
+	// Page is the page number, it starts from 1
+	Page int64
+	// PageSize is the max row count of the page
+	PageSize int64
+	// SkipCount skips the count query, PageSize+1 rows are queried to know whether there is a next page,
+	// the TotalRecord and TotalPages of PageInfo are -1
+	SkipCount bool
+}
+
+// PageInfo is the page number info of the Result of QueryPaging
+type PageInfo struct {
+	Page        int64
+	PageSize    int64
+	TotalRecord int64
+	TotalPages  int64
+	HasNext     bool
+	HasPrev     bool
+}
+
+// rows returns the start row and end row of the page
+func (p Paging) rows() (start, end int64, err error) {
+	if p.Page < 1 || p.PageSize < 1 {
+		return 0, 0, errorFmt(errorWrongPaging, p.Page, p.PageSize)
+	}
+	return (p.Page-1)*p.PageSize + 1, p.Page * p.PageSize, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func TestPaging_Rows(t *testing.T) {
+	tests := []struct {
+		in    Paging
+		start int64
+		end   int64
+		err   bool
+	}{
+		{Paging{Page: 1, PageSize: 10}, 1, 10, false},
+		{Paging{Page: 3, PageSize: 10}, 21, 30, false},
+		{Paging{Page: 0, PageSize: 10}, 0, 0, true},
+		{Paging{Page: 1, PageSize: 0}, 0, 0, true},
+	}
+	for _, v := range tests {
+		start, end, err := v.in.rows()
+		assert.Equal(t, v.err, err != nil)
+		assert.Equal(t, v.start, start)
+		assert.Equal(t, v.end, end)
+	}
+}
+
+func TestAgent_QueryPaging(t *testing.T) {
+	cols := []testColumn{{name: "ID", dbType: "BIGINT", scanType: reflect.TypeOf(int64(0))}}
+	query := "SELECT * FROM (SELECT ID FROM T3) AS TBS1 ORDER BY ID LIMIT ? OFFSET ?"
+	testSetQuery(query, cols, []driver.Value{int64(3)}, []driver.Value{int64(4)}, []driver.Value{int64(5)})
+	testSetQuery("SELECT COUNT(1) AS TOTALRECORD FROM (SELECT ID FROM T3) DATA",
+		[]testColumn{{name: "TOTALRECORD", dbType: "BIGINT"}}, []driver.Value{int64(5)})
+	a := &Agent{db: testOpenDB(), t: MySql}
+
+	res, err := a.QueryPagingWithSql("SELECT ID FROM T3", "ID", Paging{Page: 2, PageSize: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, PageInfo{Page: 2, PageSize: 2, TotalRecord: 5, TotalPages: 3, HasNext: true, HasPrev: true}, res.(PagingResult).PageInfo())
+	assert.Equal(t, []driver.Value{int64(2), int64(2)}, testTakeArgs(query))
+
+	res, err = a.QueryPagingWithSql("SELECT ID FROM T3", "ID", Paging{Page: 2, PageSize: 2, SkipCount: true})
+	assert.Nil(t, err)
+	assert.Equal(t, PageInfo{Page: 2, PageSize: 2, TotalRecord: -1, TotalPages: -1, HasNext: true, HasPrev: true}, res.(PagingResult).PageInfo())
+	assert.Equal(t, []map[string]interface{}{{"ID": int64(3)}, {"ID": int64(4)}}, res.Rows())
+	assert.Equal(t, [][]interface{}{{int64(3)}, {int64(4)}}, res.(ColumnResult).Values())
+	assert.Equal(t, []driver.Value{int64(3), int64(2)}, testTakeArgs(query))
+
+	_, err = a.QueryPagingWithSql("SELECT ID FROM T3", "ID", Paging{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type postgreSqlDialect struct{}
+
+func (postgreSqlDialect) DriverName() string {
+	return "postgres"
+}
+
+func (postgreSqlDialect) Param(i int) string {
+	return fmt.Sprint("$", strconv.FormatInt(int64(i+1), 10))
+}
+
+func (postgreSqlDialect) QuoteIdentifier(name string) string {
+	return fmt.Sprint(`"`, strings.ReplaceAll(name, `"`, `""`), `"`)
+}
+
+func (d postgreSqlDialect) PageSql(sql, obs string, start, end int64, n int) (string, []interface{}) {
+	if obs != "" {
+		obs = fmt.Sprint(" ORDER BY ", obs)
+	}
+	limit, offset := pageLimit(start, end)
+	return fmt.Sprint("SELECT * FROM (", sql, ") AS TBS1", obs, " LIMIT ", d.Param(n), " OFFSET ", d.Param(n+1)),
+		[]interface{}{limit, offset}
+}
+
+func (postgreSqlDialect) CountSql(sql string) string {
+	return getCountSql(sql)
+}
+
+func (postgreSqlDialect) ExistsSql(sql string) string {
+	return fmt.Sprint("SELECT CASE WHEN EXISTS(", sql, ") THEN 'Y' ELSE 'N' END AS E")
+}
+
+func (postgreSqlDialect) TablesSql(string) (string, []interface{}) {
+	return sqlQueryTablesPostgreSql, nil
+}
+
+func (postgreSqlDialect) TableSchemaSql(_, table string) (string, []interface{}) {
+	return sqlQueryTableSchemaPostgreSql, []interface{}{table}
+}
+
+func (postgreSqlDialect) MaxParams() int {
+	return 65535
+}
+
+func (postgreSqlDialect) MaxRows() int {
+	return 0
+}
+
+func (d postgreSqlDialect) BatchInsertSql(table string, cols []string, n int) string {
+	return getValuesInsertSql(d, table, cols, n)
+}
+
+func (d postgreSqlDialect) UpsertSql(table string, cols, conflictCols, updateCols []string, n int) string {
+	return getOnConflictSql(d, table, cols, conflictCols, updateCols, n)
+}
+
+func (postgreSqlDialect) LastInsertIdSql(insertSql string) string {
+	return fmt.Sprint(insertSql, " RETURNING id")
+}
+
+func (postgreSqlDialect) SavepointSql(name string) (savepoint, rollback, release string) {
+	return fmt.Sprint("SAVEPOINT ", name), fmt.Sprint("ROLLBACK TO SAVEPOINT ", name), fmt.Sprint("RELEASE SAVEPOINT ", name)
+}
+
+// IsRetryable reports whether err is SQLSTATE 40001 serialization failure or 40P01 deadlock
+func (postgreSqlDialect) IsRetryable(err error) bool {
+	return matchError(err, func(e error) bool {
+		s := errorState(e)
+		return s == "40001" || s == "40P01"
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// paramPattern matches @{key} and @{key.property}, the submatches are key and property path
+	paramPattern = regexp.MustCompile(`@\{(\w+)((?:\.\w+|\[\d+\])*)\}`)
+	// pathPattern matches the param key with property path in <if test>, e.g. user.address.city or items[0]
+	pathPattern = regexp.MustCompile(`[A-Za-z_]\w*(?:\.\w+|\[\d+\])+`)
+)
+
+// unquotedMatches returns the submatch indexes of pattern in s, except the matches in the quoted strings
+func unquotedMatches(pattern *regexp.Regexp, s string) [][]int {
+	var quoted [][2]int
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\'' && s[i] != '"' {
+			continue
+		}
+		end := strings.IndexByte(s[i+1:], s[i])
+		if end < 0 {
+			end = len(s)
+		} else {
+			end += i + 1
+		}
+		quoted = append(quoted, [2]int{i, end})
+		i = end
+	}
+	var matches [][]int
+	for _, m := range pattern.FindAllStringSubmatchIndex(s, -1) {
+		in := false
+		for _, q := range quoted {
+			if m[0] > q[0] && m[0] < q[1] {
+				in = true
+				break
+			}
+		}
+		if !in {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// propertyValue returns the value of the property path of v, e.g. .address.city or [0].id
+// the struct field is matched by the jsql tag, json tag or field name case-insensitively,
+// a nil value, missing map key or out of range index in the path returns nil
+func propertyValue(v interface{}, path string) (interface{}, error) {
+	return property(v, path, false)
+}
+
+// namedValue returns the value of the property path of v same as propertyValue,
+// but a nil value, missing map key or out of range index in the path returns error
+func namedValue(v interface{}, path string) (interface{}, error) {
+	return property(v, path, true)
+}
+
+func property(v interface{}, path string, strict bool) (interface{}, error) {
+	// unresolved returns nil, or error if strict
+	unresolved := func() (interface{}, error) {
+		if strict {
+			return nil, errorFmt(errorUnknownProperty, path, v)
+		}
+		return nil, nil
+	}
+	rv := reflect.ValueOf(v)
+	for p := path; p != ""; {
+		for rv.IsValid() && (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) {
+			if rv.IsNil() {
+				return unresolved()
+			}
+			rv = rv.Elem()
+		}
+		if !rv.IsValid() {
+			return unresolved()
+		}
+		switch p[0] {
+		case '.':
+			name := p[1:]
+			if i := strings.IndexAny(name, ".["); i >= 0 {
+				name = name[:i]
+			}
+			p = p[1+len(name):]
+			switch rv.Kind() {
+			case reflect.Map:
+				if rv.Type().Key().Kind() != reflect.String {
+					return nil, errorFmt(errorWrongProperty, path, v)
+				}
+				mv := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+				if !mv.IsValid() {
+					// the struct param is converted to map by json, so the key case may differ
+					for iter := rv.MapRange(); iter.Next(); {
+						if strings.EqualFold(iter.Key().String(), name) {
+							mv = iter.Value()
+							break
+						}
+					}
+				}
+				rv = mv
+			case reflect.Struct:
+				index, ok := structFields(rv.Type())[strings.ToLower(name)]
+				if !ok {
+					return nil, errorFmt(errorWrongProperty, path, v)
+				}
+				var err error
+				if rv, err = rv.FieldByIndexErr(index); err != nil {
+					// nil embedded struct pointer
+					return unresolved()
+				}
+			default:
+				return nil, errorFmt(errorWrongProperty, path, v)
+			}
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, errorFmt(errorWrongProperty, path, v)
+			}
+			i, err := strconv.Atoi(p[1:end])
+			if err != nil || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+				return nil, errorFmt(errorWrongProperty, path, v)
+			}
+			if i < 0 || i >= rv.Len() {
+				// same as a missing map key
+				return unresolved()
+			}
+			rv = rv.Index(i)
+			p = p[end+1:]
+		default:
+			return nil, errorFmt(errorWrongProperty, path, v)
+		}
+	}
+	if !rv.IsValid() {
+		return unresolved()
+	}
+	return rv.Interface(), nil
+}
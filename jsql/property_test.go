@@ -0,0 +1,45 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPropertyValue(t *testing.T) {
+	type address struct {
+		City string `jsql:"CITY"`
+	}
+	type user struct {
+		Name    string
+		Address *address
+		Items   []map[string]interface{}
+	}
+	u := user{Name: "a", Address: &address{City: "b"}, Items: []map[string]interface{}{{"id": 1}}}
+	tests := []struct {
+		in   interface{}
+		path string
+		out  interface{}
+		err  bool
+	}{
+		{u, "", u, false},
+		{u, ".name", "a", false},
+		{&u, ".address.city", "b", false},
+		{u, ".items[0].id", 1, false},
+		{user{}, ".address.city", nil, false},
+		{map[string]interface{}{"a": nil}, ".a.b", nil, false},
+		{map[string]interface{}{"Address": map[string]interface{}{"City": "c"}}, ".address.city", "c", false},
+		{u, ".items[1].id", nil, false},
+		{u, ".name[0]", nil, true},
+		{u, ".unknown", nil, true},
+		{1, ".a", nil, true},
+	}
+	for _, v := range tests {
+		out, err := propertyValue(v.in, v.path)
+		assert.Equal(t, v.err, err != nil, v.path)
+		assert.Equal(t, v.out, out, v.path)
+	}
+}
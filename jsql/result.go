@@ -4,6 +4,31 @@
 
 package jsql
 
+import (
+	"database/sql"
+	"github.com/xjustloveux/jgo/jcast"
+	"sort"
+	"strings"
+)
+
+// Column is the column info of Result from sql.ColumnType
+type Column struct {
+	// Name is the column name
+	Name string
+	// DatabaseType is the database system name of the column type, e.g. VARCHAR, DECIMAL
+	DatabaseType string
+	// Nullable reports whether the column may be null, NullableOk is false if the driver does not support it
+	Nullable   bool
+	NullableOk bool
+	// Length is the length of the variable length column types, LengthOk is false if it is not variable length
+	Length   int64
+	LengthOk bool
+	// Precision and Scale are the decimal size of the decimal types, PrecisionOk is false if it is not decimal
+	Precision   int64
+	Scale       int64
+	PrecisionOk bool
+}
+
 type Result interface {
 	// Row returns query row data
 	// if multiple row then return first row data
@@ -31,11 +56,40 @@ type Result interface {
 	RowsAffected() (int64, error)
 }
 
+// ColumnResult is the Result with the query columns,
+// the Result of the query can be asserted to ColumnResult
+type ColumnResult interface {
+	Result
+	// Columns returns the query columns in select order
+	Columns() []Column
+	// Values returns the query rows data whose values are in the order of Columns
+	Values() [][]interface{}
+	// Get returns the value of column col of the row index, the col is case-insensitive, the exact match is preferred
+	// returns nil if there is no such row or column
+	Get(row int, col string) interface{}
+	// GetString returns the value of Get as string, nil value returns empty string
+	GetString(row int, col string) string
+	// GetInt64 returns the value of Get as int64, nil value returns zero
+	GetInt64(row int, col string) (int64, error)
+}
+
+// PagingResult is the Result with the page number info,
+// the Result of QueryPaging can be asserted to PagingResult
+type PagingResult interface {
+	Result
+	// PageInfo returns the page number info of QueryPaging
+	// if not query paging, the value default zero
+	PageInfo() PageInfo
+}
+
 type agentResult struct {
 	rows         []map[string]interface{}
+	columns      []Column
+	values       [][]interface{}
 	rowStart     int64
 	rowEnd       int64
 	totalRecord  int64
+	pageInfo     PageInfo
 	lastInsertId lastInsertId
 	rowsAffected rowsAffected
 }
@@ -51,6 +105,113 @@ func (result agentResult) Rows() []map[string]interface{} {
 	return result.rows
 }
 
+func (result agentResult) Columns() []Column {
+	return result.columns
+}
+
+func (result agentResult) Values() [][]interface{} {
+	if result.values != nil {
+		return result.values
+	}
+	values := make([][]interface{}, len(result.rows))
+	for i, row := range result.rows {
+		values[i] = make([]interface{}, len(result.columns))
+		for j, col := range result.columns {
+			values[i][j] = row[col.Name]
+		}
+	}
+	return values
+}
+
+func (result agentResult) Get(row int, col string) interface{} {
+	if row < 0 || row >= len(result.rows) {
+		return nil
+	}
+	if v, ok := result.rows[row][col]; ok {
+		return v
+	}
+	// the first case-insensitive match in column order, the columns may differ only by case
+	for _, c := range result.columns {
+		if strings.EqualFold(c.Name, col) {
+			return result.rows[row][c.Name]
+		}
+	}
+	if len(result.columns) > 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(result.rows[row]))
+	for k := range result.rows[row] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.EqualFold(k, col) {
+			return result.rows[row][k]
+		}
+	}
+	return nil
+}
+
+func (result agentResult) GetString(row int, col string) string {
+	v := result.Get(row, col)
+	if v == nil {
+		return ""
+	}
+	return jcast.String(v)
+}
+
+func (result agentResult) GetInt64(row int, col string) (int64, error) {
+	v := result.Get(row, col)
+	if v == nil {
+		return 0, nil
+	}
+	return jcast.Int64(v)
+}
+
+// withoutColumn returns the result without column col, the col is case-insensitive
+func (result agentResult) withoutColumn(col string) agentResult {
+	columns := make([]Column, 0, len(result.columns))
+	var index []int
+	for i, c := range result.columns {
+		if !strings.EqualFold(c.Name, col) {
+			columns = append(columns, c)
+			index = append(index, i)
+		}
+	}
+	if result.values != nil && len(index) < len(result.columns) {
+		values := make([][]interface{}, len(result.values))
+		for i, value := range result.values {
+			values[i] = make([]interface{}, len(index))
+			for j, k := range index {
+				values[i][j] = value[k]
+			}
+		}
+		result.values = values
+	}
+	result.columns = columns
+	for _, row := range result.rows {
+		for k := range row {
+			if strings.EqualFold(k, col) {
+				delete(row, k)
+			}
+		}
+	}
+	return result
+}
+
+// newColumns returns the Column of colTypes
+func newColumns(colTypes []*sql.ColumnType) []Column {
+	columns := make([]Column, len(colTypes))
+	for i, ct := range colTypes {
+		c := Column{Name: ct.Name(), DatabaseType: ct.DatabaseTypeName()}
+		c.Nullable, c.NullableOk = ct.Nullable()
+		c.Length, c.LengthOk = ct.Length()
+		c.Precision, c.Scale, c.PrecisionOk = ct.DecimalSize()
+		columns[i] = c
+	}
+	return columns
+}
+
 func (result agentResult) RowStart() int64 {
 	return result.rowStart
 }
@@ -63,6 +224,10 @@ func (result agentResult) TotalRecord() int64 {
 	return result.totalRecord
 }
 
+func (result agentResult) PageInfo() PageInfo {
+	return result.pageInfo
+}
+
 func (result agentResult) LastInsertId() (int64, error) {
 	return result.lastInsertId.id, result.lastInsertId.err
 }
@@ -5,11 +5,50 @@
 package jsql
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"reflect"
 	"testing"
 )
 
+var (
+	_ Result       = testResult{}
+	_ ColumnResult = agentResult{}
+	_ PagingResult = agentResult{}
+)
+
+// testResult implements Result only, e.g. a mock of the user
+type testResult struct {
+	rows []map[string]interface{}
+}
+
+func (r testResult) Row() map[string]interface{}    { return r.rows[0] }
+func (r testResult) Rows() []map[string]interface{} { return r.rows }
+func (r testResult) RowStart() int64                { return 1 }
+func (r testResult) RowEnd() int64                  { return int64(len(r.rows)) }
+func (r testResult) TotalRecord() int64             { return int64(len(r.rows)) }
+func (r testResult) LastInsertId() (int64, error)   { return -1, nil }
+func (r testResult) RowsAffected() (int64, error)   { return 0, nil }
+
+func TestResult_Implementation(t *testing.T) {
+	var page struct {
+		Rows        []map[string]interface{}
+		TotalRecord int64
+		PageInfo    PageInfo
+	}
+	r := testResult{rows: []map[string]interface{}{{"ID": int64(1)}}}
+	assert.Nil(t, mapRows(r, true, &page))
+	assert.Equal(t, r.rows, page.Rows)
+	assert.Equal(t, int64(1), page.TotalRecord)
+	assert.Equal(t, PageInfo{}, page.PageInfo)
+	p, err := toPage[map[string]interface{}](r)
+	assert.Nil(t, err)
+	assert.Equal(t, r.rows, p.Rows)
+	assert.Equal(t, PageInfo{}, p.PageInfo)
+}
+
 func TestAgentResult_Row(t *testing.T) {
 	row := make(map[string]interface{})
 	tests := []struct {
@@ -113,3 +152,76 @@ func TestAgentResult_RowsAffected(t *testing.T) {
 		assert.Equal(t, err, test.output.err, fmt.Sprintf("%v != %v", err, test.output.err))
 	}
 }
+
+func TestAgentResult_Columns(t *testing.T) {
+	cols := []testColumn{
+		{name: "name", dbType: "VARCHAR", scanType: reflect.TypeOf(""), nullable: true, length: 20},
+		{name: "AMOUNT", dbType: "DECIMAL", scanType: reflect.TypeOf(sql.RawBytes{}), precision: 10, scale: 2},
+		{name: "Id", dbType: "BIGINT", scanType: reflect.TypeOf(int64(0))},
+	}
+	query := "SELECT name, AMOUNT, Id FROM T"
+	testSetQuery(query, cols, []driver.Value{"a", []byte("1.5"), int64(1)}, []driver.Value{nil, []byte("2"), int64(2)})
+	a := &Agent{db: testOpenDB(), t: PostgreSql}
+	result, err := a.QueryWithSql(query)
+	assert.Nil(t, err)
+	res, ok := result.(ColumnResult)
+	assert.True(t, ok)
+	assert.Equal(t, []Column{
+		{Name: "name", DatabaseType: "VARCHAR", Nullable: true, NullableOk: true, Length: 20, LengthOk: true},
+		{Name: "AMOUNT", DatabaseType: "DECIMAL", NullableOk: true, Precision: 10, Scale: 2, PrecisionOk: true},
+		{Name: "Id", DatabaseType: "BIGINT", NullableOk: true},
+	}, res.Columns())
+	assert.Equal(t, [][]interface{}{{"a", 1.5, int64(1)}, {nil, float64(2), int64(2)}}, res.Values())
+	assert.Equal(t, "a", res.Get(0, "NAME"))
+	assert.Equal(t, int64(2), res.Get(1, "id"))
+	assert.Nil(t, res.Get(1, "name"))
+	assert.Nil(t, res.Get(2, "name"))
+	assert.Nil(t, res.Get(0, "unknown"))
+	assert.Equal(t, "a", res.GetString(0, "Name"))
+	assert.Equal(t, "", res.GetString(1, "Name"))
+	assert.Equal(t, "1.5", res.GetString(0, "amount"))
+	i, err := res.GetInt64(1, "AMOUNT")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), i)
+	i, err = res.GetInt64(1, "NAME")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), i)
+	_, err = res.GetInt64(0, "NAME")
+	assert.NotNil(t, err)
+
+	// the values of the same column name are kept in column order
+	query = "SELECT a.ID, b.ID FROM A a JOIN B b ON a.REF = b.ID"
+	testSetQuery(query, []testColumn{{name: "ID", dbType: "BIGINT"}, {name: "ID", dbType: "BIGINT"}},
+		[]driver.Value{int64(1), int64(2)}, []driver.Value{int64(3), int64(4)})
+	result, err = a.QueryWithSql(query)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]interface{}{{int64(1), int64(2)}, {int64(3), int64(4)}}, result.(ColumnResult).Values())
+
+	// the exact match first, then the first case-insensitive match in column order
+	r := agentResult{
+		rows:    []map[string]interface{}{{"b": 1, "Name": 2, "NAME": 3}},
+		columns: []Column{{Name: "b"}, {Name: "Name"}, {Name: "NAME"}},
+	}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, 3, r.Get(0, "NAME"))
+		assert.Equal(t, 2, r.Get(0, "Name"))
+		assert.Equal(t, 2, r.Get(0, "name"))
+		assert.Equal(t, 1, r.Get(0, "B"))
+	}
+	r.columns = nil
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, 3, r.Get(0, "name"))
+	}
+}
+
+func TestAgent_DeletePagingId(t *testing.T) {
+	res := agentResult{
+		rows:    []map[string]interface{}{{"ID": 2, "allowpagingid": 1}},
+		columns: []Column{{Name: "ID"}, {Name: "allowpagingid"}, {Name: "ID"}},
+		values:  [][]interface{}{{1, 1, 2}},
+	}
+	r := (&Agent{t: PostgreSql}).deletePagingId(res).(agentResult)
+	assert.Equal(t, []map[string]interface{}{{"ID": 2}}, r.Rows())
+	assert.Equal(t, []Column{{Name: "ID"}, {Name: "ID"}}, r.Columns())
+	assert.Equal(t, [][]interface{}{{1, 2}}, r.Values())
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// RetryPolicy is the retry policy of Agent.UseTx
+// the whole block is retried only when the error is retryable for the db Type
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one, less than 2 means no retry
+	MaxAttempts int
+	// Backoff is the delay before the first retry, it is doubled for each next retry
+	Backoff time.Duration
+	// MaxBackoff is the upper bound of the delay, 0 means no upper bound
+	MaxBackoff time.Duration
+	// Jitter adds a random delay between 0 and Jitter * delay, it should be between 0 and 1
+	Jitter float64
+	// Retryable overrides Type.IsRetryable if it is not nil
+	Retryable func(t Type, err error) bool
+}
+
+// RetryEvent is sent to the SubscribeRetry events before each retry
+type RetryEvent struct {
+	Type    Type
+	DbName  string
+	Attempt int
+	Delay   time.Duration
+	Err     error
+}
+
+func (p RetryPolicy) retryable(t Type, err error, attempt int) bool {
+	if err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(t, err)
+	}
+	return t.IsRetryable(err)
+}
+
+// delay returns the delay before the next attempt after attempt failed
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d > 0 && d <= math.MaxInt64/2; i++ {
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 && d > 0 {
+		d += time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+	return d
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// errorNumber returns the vendor error number of the driver error
+// e.g. mysql.MySQLError.Number, mssql.Error.Number or godror.OraErr.Code()
+func errorNumber(err error) (int64, bool) {
+	switch e := err.(type) {
+	case interface{ SQLErrorNumber() int32 }:
+		return int64(e.SQLErrorNumber()), true
+	case interface{ Code() int }:
+		return int64(e.Code()), true
+	}
+	if v := errorField(err, "Number"); v.IsValid() {
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Int(), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return int64(v.Uint()), true
+		}
+	}
+	return 0, false
+}
+
+// errorState returns the SQLSTATE of the driver error
+// e.g. pq.Error.Code or pgconn.PgError.SQLState()
+func errorState(err error) string {
+	if e, ok := err.(interface{ SQLState() string }); ok {
+		return e.SQLState()
+	}
+	if v := errorField(err, "Code"); v.IsValid() && v.Kind() == reflect.String {
+		return v.String()
+	}
+	return ""
+}
+
+// matchError reports whether err or any error wrapped by err matches f
+func matchError(err error, f func(error) bool) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if f(err) {
+			return true
+		}
+	}
+	return false
+}
+
+func errorField(err error, name string) reflect.Value {
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return v.FieldByName(name)
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testNumberError struct {
+	Number uint16
+}
+
+func (e *testNumberError) Error() string {
+	return fmt.Sprint("Error ", e.Number)
+}
+
+type testCodeError struct {
+	Code string
+}
+
+func (e testCodeError) Error() string {
+	return fmt.Sprint("pq: ", e.Code)
+}
+
+type testOraError int
+
+func (e testOraError) Error() string {
+	return fmt.Sprintf("ORA-%05d", int(e))
+}
+
+func (e testOraError) Code() int {
+	return int(e)
+}
+
+func TestType_IsRetryable(t *testing.T) {
+	tests := []struct {
+		t   Type
+		err error
+		out bool
+	}{
+		{MySql, &testNumberError{Number: 1213}, true},
+		{MySql, fmt.Errorf("wrap: %w", &testNumberError{Number: 1213}), true},
+		{MySql, &testNumberError{Number: 1062}, false},
+		{MSSql, &testNumberError{Number: 1205}, true},
+		{MSSql, &testNumberError{Number: 1213}, false},
+		{Oracle, testOraError(60), true},
+		{Oracle, testOraError(8177), true},
+		{Oracle, errors.New("ORA-08177: can't serialize access for this transaction"), true},
+		{Oracle, testOraError(1), false},
+		{PostgreSql, testCodeError{Code: "40001"}, true},
+		{PostgreSql, testCodeError{Code: "40P01"}, true},
+		{PostgreSql, testCodeError{Code: "23505"}, false},
+		{Unknown, &testNumberError{Number: 1213}, false},
+		{MySql, nil, false},
+	}
+	for _, v := range tests {
+		out := v.t.IsRetryable(v.err)
+		assert.Equal(t, v.out, out, fmt.Sprintf("%v %v: %v != %v", v.t, v.err, out, v.out))
+	}
+}
+
+func TestRetryPolicy_Retryable(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3}
+	err := &testNumberError{Number: 1213}
+	assert.True(t, p.retryable(MySql, err, 1))
+	assert.True(t, p.retryable(MySql, err, 2))
+	assert.False(t, p.retryable(MySql, err, 3))
+	assert.False(t, p.retryable(MySql, nil, 1))
+	p.Retryable = func(Type, error) bool { return false }
+	assert.False(t, p.retryable(MySql, err, 1))
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	tests := []struct {
+		in  int
+		out time.Duration
+	}{
+		{1, 10 * time.Millisecond},
+		{2, 20 * time.Millisecond},
+		{3, 40 * time.Millisecond},
+		{4, 50 * time.Millisecond},
+		{100, 50 * time.Millisecond},
+	}
+	for _, v := range tests {
+		d := p.delay(v.in)
+		assert.Equal(t, v.out, d, fmt.Sprintf("%v != %v", d, v.out))
+	}
+	p.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		d := p.delay(1)
+		assert.True(t, d >= 10*time.Millisecond && d <= 15*time.Millisecond, fmt.Sprintf("%v out of range", d))
+	}
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.delay(3))
+	assert.True(t, RetryPolicy{Backoff: time.Second}.delay(100) > 0)
+}
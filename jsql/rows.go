@@ -0,0 +1,103 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Rows is a cursor of query result, it reads one row at a time
+// the Rows must be closed, it is also closed when Next returns false
+type Rows struct {
+	agent    *Agent
+	rows     *sql.Rows
+	colTypes []*sql.ColumnType
+	record   map[string]interface{}
+	cancel   context.CancelFunc
+	err      error
+	closed   bool
+}
+
+func newRows(a *Agent, rows *sql.Rows, cancel context.CancelFunc) (*Rows, error) {
+	if cancel == nil {
+		cancel = func() {}
+	}
+	if rows == nil {
+		cancel()
+		return nil, errorStr(errorRowsNil)
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		cancel()
+		if e := rows.Close(); e != nil {
+			return nil, e
+		}
+		return nil, err
+	}
+	return &Rows{agent: a, rows: rows, colTypes: colTypes, cancel: cancel}, nil
+}
+
+// Columns returns the column names
+func (r *Rows) Columns() []string {
+	cols := make([]string, len(r.colTypes))
+	for i, colType := range r.colTypes {
+		cols[i] = colType.Name()
+	}
+	return cols
+}
+
+// Next prepares the next row for reading with Record or Scan
+// it returns false and closes the Rows if there is no next row or an error occurred
+func (r *Rows) Next() bool {
+	if r.closed {
+		return false
+	}
+	if !r.rows.Next() {
+		r.err = r.rows.Err()
+		_ = r.Close()
+		return false
+	}
+	if r.record, r.err = r.agent.scanRecord(r.rows, r.colTypes); r.err != nil {
+		_ = r.Close()
+		return false
+	}
+	return true
+}
+
+// Record returns the current row data
+func (r *Rows) Record() map[string]interface{} {
+	return r.record
+}
+
+// Scan sets the current row data into v, the v must be a struct point or map point
+func (r *Rows) Scan(v interface{}) error {
+	if r.record == nil {
+		return errorStr(errorNoRowsAvailable)
+	}
+	return mapTo(r.record, v)
+}
+
+// Err returns the error encountered during iteration
+func (r *Rows) Err() error {
+	return r.err
+}
+
+// Close closes the Rows, it is safe to call Close multiple times
+func (r *Rows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.record = nil
+	defer r.cancel()
+	if err := r.rows.Close(); err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return err
+	}
+	return nil
+}
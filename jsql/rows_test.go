@@ -0,0 +1,81 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func TestRows(t *testing.T) {
+	cols := []testColumn{
+		{name: "ID", dbType: "BIGINT", scanType: reflect.TypeOf(int64(0))},
+		{name: "NAME", dbType: "VARCHAR"},
+	}
+	testSetQuery("SELECT ID, NAME FROM TEST_ROWS", cols,
+		[]driver.Value{int64(1), []byte("A")},
+		[]driver.Value{int64(2), []byte("B")},
+		[]driver.Value{int64(3), nil})
+	a := &Agent{db: testOpenDB(), t: MySql}
+	rows, err := a.QueryIterWithSql("SELECT ID, NAME FROM TEST_ROWS")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"ID", "NAME"}, rows.Columns())
+	records := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		records = append(records, rows.Record())
+	}
+	assert.Nil(t, rows.Err())
+	assert.Equal(t, []map[string]interface{}{
+		{"ID": int64(1), "NAME": "A"},
+		{"ID": int64(2), "NAME": "B"},
+		{"ID": int64(3), "NAME": nil},
+	}, records)
+	assert.False(t, rows.Next())
+	assert.Nil(t, rows.Close())
+
+	rows, err = a.QueryIterWithSql("SELECT ID, NAME FROM TEST_ROWS")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var v struct {
+		ID   int64
+		NAME string
+	}
+	assert.True(t, rows.Next())
+	assert.Nil(t, rows.Scan(&v))
+	assert.Equal(t, int64(1), v.ID)
+	assert.Equal(t, "A", v.NAME)
+	assert.Nil(t, rows.Close())
+	assert.False(t, rows.Next())
+	assert.NotNil(t, rows.Scan(&v))
+}
+
+func TestRows_Tx(t *testing.T) {
+	testSetQuery("SELECT 1 AS ID", []testColumn{{name: "ID", dbType: "BIGINT", scanType: reflect.TypeOf(int64(0))}},
+		[]driver.Value{int64(1)})
+	a := &Agent{db: testOpenDB(), t: MySql}
+	_, err := a.QueryIterTxWithSql("SELECT 1 AS ID")
+	assert.Equal(t, errorStr(errorDbNotBegin), err)
+	testTakeExecs()
+	tx, err := a.BeginTxContext(context.Background(), nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	rows, err := tx.QueryIterWithSql("SELECT 1 AS ID")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, rows.Next())
+	assert.Equal(t, map[string]interface{}{"ID": int64(1)}, rows.Record())
+	assert.False(t, rows.Next())
+	assert.Nil(t, rows.Err())
+	assert.Nil(t, tx.Commit())
+	assert.Equal(t, []string{"BEGIN", "COMMIT"}, testTakeExecs())
+}
@@ -5,6 +5,7 @@ const (
 	sqlQueryTablesMSSql      = `SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES ORDER BY TABLE_NAME`
 	sqlQueryTablesOracle     = `SELECT OBJECT_NAME AS TABLE_NAME FROM USER_OBJECTS WHERE OBJECT_TYPE IN ('TABLE', 'VIEW') ORDER BY TABLE_NAME`
 	sqlQueryTablesPostgreSql = `SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = 'public' ORDER BY TABLE_NAME`
+	sqlQueryTablesSqlite     = `SELECT name AS TABLE_NAME FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name`
 
 	sqlQueryTableSchemaMySql = `SELECT
     								DISTINCT
@@ -173,4 +174,18 @@ const (
 									AND C.TABLE_NAME = $1
 
 								ORDER BY C.ORDINAL_POSITION`
+	sqlQueryTableSchemaSqlite = `SELECT
+									P.name AS COLUMN_NAME,
+									P.type AS DATA_TYPE,
+									CASE WHEN P."notnull" = 1 THEN 'NO' ELSE 'YES' END AS IS_NULLABLE,
+									P.dflt_value AS DATA_DEFAULT,
+									CASE WHEN P.pk > 0 THEN P.pk END AS PRIMARY_KEY,
+									CASE WHEN P.pk = 1 AND UPPER(P.type) = 'INTEGER' THEN 'YES' ELSE 'NO' END AS IS_IDENTITY,
+									'' AS COLUMN_COMMENT,
+									'' AS TABLE_COMMENT,
+									P.cid + 1 AS ORDINAL_POSITION
+
+								FROM PRAGMA_TABLE_INFO(?) AS P
+
+								ORDER BY P.cid`
 )
@@ -0,0 +1,77 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) DriverName() string {
+	return "sqlite3"
+}
+
+func (sqliteDialect) Param(int) string {
+	return "?"
+}
+
+func (sqliteDialect) QuoteIdentifier(name string) string {
+	return fmt.Sprint(`"`, strings.ReplaceAll(name, `"`, `""`), `"`)
+}
+
+func (sqliteDialect) PageSql(sql, obs string, start, end int64, _ int) (string, []interface{}) {
+	if obs != "" {
+		obs = fmt.Sprint(" ORDER BY ", obs)
+	}
+	limit, offset := pageLimit(start, end)
+	return fmt.Sprint("SELECT * FROM (", sql, ") AS TBS1", obs, " LIMIT ? OFFSET ?"), []interface{}{limit, offset}
+}
+
+func (sqliteDialect) CountSql(sql string) string {
+	return getCountSql(sql)
+}
+
+func (sqliteDialect) ExistsSql(sql string) string {
+	return fmt.Sprint("SELECT CASE WHEN EXISTS(", sql, ") THEN 'Y' ELSE 'N' END AS E")
+}
+
+func (sqliteDialect) TablesSql(string) (string, []interface{}) {
+	return sqlQueryTablesSqlite, nil
+}
+
+func (sqliteDialect) TableSchemaSql(_, table string) (string, []interface{}) {
+	return sqlQueryTableSchemaSqlite, []interface{}{table}
+}
+
+// MaxParams is the default SQLITE_MAX_VARIABLE_NUMBER since SQLite 3.32.0
+func (sqliteDialect) MaxParams() int {
+	return 32766
+}
+
+func (sqliteDialect) MaxRows() int {
+	return 0
+}
+
+func (d sqliteDialect) BatchInsertSql(table string, cols []string, n int) string {
+	return getValuesInsertSql(d, table, cols, n)
+}
+
+func (d sqliteDialect) UpsertSql(table string, cols, conflictCols, updateCols []string, n int) string {
+	return getOnConflictSql(d, table, cols, conflictCols, updateCols, n)
+}
+
+func (sqliteDialect) LastInsertIdSql(insertSql string) string {
+	return fmt.Sprint(insertSql, " RETURNING rowid")
+}
+
+func (sqliteDialect) SavepointSql(name string) (savepoint, rollback, release string) {
+	return fmt.Sprint("SAVEPOINT ", name), fmt.Sprint("ROLLBACK TO SAVEPOINT ", name), fmt.Sprint("RELEASE SAVEPOINT ", name)
+}
+
+func (sqliteDialect) IsRetryable(error) bool {
+	return false
+}
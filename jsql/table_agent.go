@@ -5,10 +5,11 @@
 package jsql
 
 import (
+	"context"
 	"fmt"
-	"github.com/xjustloveux/jgo/jfile"
 	"github.com/xjustloveux/jgo/jruntime"
 	"reflect"
+	"sort"
 )
 
 type TableAgent struct {
@@ -162,15 +163,19 @@ func (ta *TableAgent) LessThanOrEqual(col string, val interface{}) {
 
 // Query executes a query that returns Result
 func (ta *TableAgent) Query(v ...interface{}) (Result, error) {
+	return ta.QueryContext(context.Background(), v...)
+}
+
+// QueryContext executes a query with context that returns Result
+func (ta *TableAgent) QueryContext(ctx context.Context, v ...interface{}) (Result, error) {
 	if query, args, err := ta.getQueryAndArgs(); err != nil {
 		return nil, err
 	} else {
 		var r Result
-		if r, err = ta.Agent.QueryWithSql(query, args...); err != nil || len(v) == 0 {
+		if r, err = ta.Agent.query(ctx, nil, false, query, args...); err != nil || len(v) == 0 {
 			return r, err
 		} else {
-			m := map[string]interface{}{"Rows": r.Rows()}
-			err = jfile.Convert(m, v[0])
+			err = mapRows(r, false, v[0])
 			return r, err
 		}
 	}
@@ -178,18 +183,26 @@ func (ta *TableAgent) Query(v ...interface{}) (Result, error) {
 
 // QueryTx executes a query that returns Result
 func (ta *TableAgent) QueryTx(v ...interface{}) (Result, error) {
+	return ta.QueryTxContext(context.Background(), v...)
+}
+
+// QueryTxContext executes a query with context that returns Result
+func (ta *TableAgent) QueryTxContext(ctx context.Context, v ...interface{}) (Result, error) {
 	if ta.Agent == nil {
 		return nil, errorStr(errorAgentNil)
 	}
+	tx, err := ta.Agent.currentTx()
+	if err != nil {
+		return nil, err
+	}
 	if query, args, err := ta.getQueryAndArgs(); err != nil {
 		return nil, err
 	} else {
 		var r Result
-		if r, err = ta.Agent.QueryTxWithSql(query, args...); err != nil || len(v) == 0 {
+		if r, err = ta.Agent.query(ctx, tx, false, query, args...); err != nil || len(v) == 0 {
 			return r, err
 		} else {
-			m := map[string]interface{}{"Rows": r.Rows()}
-			err = jfile.Convert(m, v[0])
+			err = mapRows(r, false, v[0])
 			return r, err
 		}
 	}
@@ -197,18 +210,19 @@ func (ta *TableAgent) QueryTx(v ...interface{}) (Result, error) {
 
 // QueryRow executes a query that is expected to return at most one row
 func (ta *TableAgent) QueryRow(v ...interface{}) (Result, error) {
+	return ta.QueryRowContext(context.Background(), v...)
+}
+
+// QueryRowContext executes a query with context that is expected to return at most one row
+func (ta *TableAgent) QueryRowContext(ctx context.Context, v ...interface{}) (Result, error) {
 	if query, args, err := ta.getQueryAndArgs(); err != nil {
 		return nil, err
 	} else {
 		var r Result
-		if r, err = ta.Agent.QueryRowWithSql(query, args...); err != nil || len(v) == 0 {
+		if r, err = ta.Agent.query(ctx, nil, true, query, args...); err != nil || len(v) == 0 {
 			return r, err
 		} else {
-			m := r.Row()
-			if m == nil {
-				m = make(map[string]interface{})
-			}
-			err = jfile.Convert(m, v[0])
+			err = mapRow(r, v[0])
 			return r, err
 		}
 	}
@@ -216,21 +230,26 @@ func (ta *TableAgent) QueryRow(v ...interface{}) (Result, error) {
 
 // QueryRowTx executes a query that is expected to return at most one row
 func (ta *TableAgent) QueryRowTx(v ...interface{}) (Result, error) {
+	return ta.QueryRowTxContext(context.Background(), v...)
+}
+
+// QueryRowTxContext executes a query with context that is expected to return at most one row
+func (ta *TableAgent) QueryRowTxContext(ctx context.Context, v ...interface{}) (Result, error) {
 	if ta.Agent == nil {
 		return nil, errorStr(errorAgentNil)
 	}
+	tx, err := ta.Agent.currentTx()
+	if err != nil {
+		return nil, err
+	}
 	if query, args, err := ta.getQueryAndArgs(); err != nil {
 		return nil, err
 	} else {
 		var r Result
-		if r, err = ta.Agent.QueryRowTxWithSql(query, args...); err != nil || len(v) == 0 {
+		if r, err = ta.Agent.query(ctx, tx, true, query, args...); err != nil || len(v) == 0 {
 			return r, err
 		} else {
-			m := r.Row()
-			if m == nil {
-				m = make(map[string]interface{})
-			}
-			err = jfile.Convert(m, v[0])
+			err = mapRow(r, v[0])
 			return r, err
 		}
 	}
@@ -239,20 +258,20 @@ func (ta *TableAgent) QueryRowTx(v ...interface{}) (Result, error) {
 // QueryPage executes a query that returns Result
 // the start and end are for query start row and end row
 func (ta *TableAgent) QueryPage(start, end int64, v ...interface{}) (Result, error) {
+	return ta.QueryPageContext(context.Background(), start, end, v...)
+}
+
+// QueryPageContext executes a query with context that returns Result
+// the start and end are for query start row and end row
+func (ta *TableAgent) QueryPageContext(ctx context.Context, start, end int64, v ...interface{}) (Result, error) {
 	if query, args, err := ta.getQuery(); err != nil {
 		return nil, err
 	} else {
 		var r Result
-		if r, err = ta.Agent.QueryPageWithSql(query, ta.OrdStr, start, end, args...); err != nil || len(v) == 0 {
+		if r, err = ta.Agent.queryPageSql(ctx, nil, query, ta.OrdStr, start, end, args...); err != nil || len(v) == 0 {
 			return r, err
 		} else {
-			m := map[string]interface{}{
-				"Rows":        r.Rows(),
-				"RowStart":    r.RowStart(),
-				"RowEnd":      r.RowEnd(),
-				"TotalRecord": r.TotalRecord(),
-			}
-			err = jfile.Convert(m, v[0])
+			err = mapRows(r, true, v[0])
 			return r, err
 		}
 	}
@@ -261,23 +280,129 @@ func (ta *TableAgent) QueryPage(start, end int64, v ...interface{}) (Result, err
 // QueryPageTx executes a query that returns Result
 // the start and end are for query start row and end row
 func (ta *TableAgent) QueryPageTx(start, end int64, v ...interface{}) (Result, error) {
+	return ta.QueryPageTxContext(context.Background(), start, end, v...)
+}
+
+// QueryPageTxContext executes a query with context that returns Result
+// the start and end are for query start row and end row
+func (ta *TableAgent) QueryPageTxContext(ctx context.Context, start, end int64, v ...interface{}) (Result, error) {
 	if ta.Agent == nil {
 		return nil, errorStr(errorAgentNil)
 	}
+	tx, err := ta.Agent.currentTx()
+	if err != nil {
+		return nil, err
+	}
 	if query, args, err := ta.getQuery(); err != nil {
 		return nil, err
 	} else {
 		var r Result
-		if r, err = ta.Agent.QueryPageTxWithSql(query, ta.OrdStr, start, end, args...); err != nil || len(v) == 0 {
+		if r, err = ta.Agent.queryPageSql(ctx, tx, query, ta.OrdStr, start, end, args...); err != nil || len(v) == 0 {
 			return r, err
 		} else {
-			m := map[string]interface{}{
-				"Rows":        r.Rows(),
-				"RowStart":    r.RowStart(),
-				"RowEnd":      r.RowEnd(),
-				"TotalRecord": r.TotalRecord(),
-			}
-			err = jfile.Convert(m, v[0])
+			err = mapRows(r, true, v[0])
+			return r, err
+		}
+	}
+}
+
+// QueryPaging executes a page number query that returns Result with PageInfo
+// the p is the page number and page size
+func (ta *TableAgent) QueryPaging(p Paging, v ...interface{}) (Result, error) {
+	return ta.QueryPagingContext(context.Background(), p, v...)
+}
+
+// QueryPagingContext executes a page number query with context that returns Result with PageInfo
+// the p is the page number and page size
+func (ta *TableAgent) QueryPagingContext(ctx context.Context, p Paging, v ...interface{}) (Result, error) {
+	if query, args, err := ta.getQuery(); err != nil {
+		return nil, err
+	} else {
+		var r Result
+		if r, err = ta.Agent.queryPagingWithSql(ctx, nil, query, ta.OrdStr, "", nil, p, args...); err != nil || len(v) == 0 {
+			return r, err
+		} else {
+			err = mapRows(r, true, v[0])
+			return r, err
+		}
+	}
+}
+
+// QueryPagingTx executes a page number query that returns Result with PageInfo
+// the p is the page number and page size
+func (ta *TableAgent) QueryPagingTx(p Paging, v ...interface{}) (Result, error) {
+	return ta.QueryPagingTxContext(context.Background(), p, v...)
+}
+
+// QueryPagingTxContext executes a page number query with context that returns Result with PageInfo
+// the p is the page number and page size
+func (ta *TableAgent) QueryPagingTxContext(ctx context.Context, p Paging, v ...interface{}) (Result, error) {
+	if ta.Agent == nil {
+		return nil, errorStr(errorAgentNil)
+	}
+	tx, err := ta.Agent.currentTx()
+	if err != nil {
+		return nil, err
+	}
+	if query, args, err := ta.getQuery(); err != nil {
+		return nil, err
+	} else {
+		var r Result
+		if r, err = ta.Agent.queryPagingWithSql(ctx, tx, query, ta.OrdStr, "", nil, p, args...); err != nil || len(v) == 0 {
+			return r, err
+		} else {
+			err = mapRows(r, true, v[0])
+			return r, err
+		}
+	}
+}
+
+// QueryKeyset executes a keyset pagination query that returns Result
+// the k is the cursor of the page, the OrdStr is replaced by the k.Column
+func (ta *TableAgent) QueryKeyset(k Keyset, v ...interface{}) (Result, error) {
+	return ta.QueryKeysetContext(context.Background(), k, v...)
+}
+
+// QueryKeysetContext executes a keyset pagination query with context that returns Result
+// the k is the cursor of the page, the OrdStr is replaced by the k.Column
+func (ta *TableAgent) QueryKeysetContext(ctx context.Context, k Keyset, v ...interface{}) (Result, error) {
+	if query, args, err := ta.getQuery(); err != nil {
+		return nil, err
+	} else {
+		var r Result
+		if r, err = ta.Agent.queryKeysetWithSql(ctx, nil, query, k, args...); err != nil || len(v) == 0 {
+			return r, err
+		} else {
+			err = mapRows(r, false, v[0])
+			return r, err
+		}
+	}
+}
+
+// QueryKeysetTx executes a keyset pagination query that returns Result
+// the k is the cursor of the page, the OrdStr is replaced by the k.Column
+func (ta *TableAgent) QueryKeysetTx(k Keyset, v ...interface{}) (Result, error) {
+	return ta.QueryKeysetTxContext(context.Background(), k, v...)
+}
+
+// QueryKeysetTxContext executes a keyset pagination query with context that returns Result
+// the k is the cursor of the page, the OrdStr is replaced by the k.Column
+func (ta *TableAgent) QueryKeysetTxContext(ctx context.Context, k Keyset, v ...interface{}) (Result, error) {
+	if ta.Agent == nil {
+		return nil, errorStr(errorAgentNil)
+	}
+	tx, err := ta.Agent.currentTx()
+	if err != nil {
+		return nil, err
+	}
+	if query, args, err := ta.getQuery(); err != nil {
+		return nil, err
+	} else {
+		var r Result
+		if r, err = ta.Agent.queryKeysetWithSql(ctx, tx, query, k, args...); err != nil || len(v) == 0 {
+			return r, err
+		} else {
+			err = mapRows(r, false, v[0])
 			return r, err
 		}
 	}
@@ -285,11 +410,16 @@ func (ta *TableAgent) QueryPageTx(start, end int64, v ...interface{}) (Result, e
 
 // Count return query count
 func (ta *TableAgent) Count() (int, error) {
+	return ta.CountContext(context.Background())
+}
+
+// CountContext return query count with context
+func (ta *TableAgent) CountContext(ctx context.Context) (int, error) {
 	if query, args, err := ta.getCountAndArgs(); err != nil {
 		return 0, err
 	} else {
 		var count int
-		if err = ta.Agent.queryRowScan(query, &count, args...); err != nil {
+		if err = ta.Agent.queryRowScan(ctx, nil, query, &count, args...); err != nil {
 			return 0, err
 		}
 		return count, nil
@@ -298,11 +428,16 @@ func (ta *TableAgent) Count() (int, error) {
 
 // CountTx return query count
 func (ta *TableAgent) CountTx() (int, error) {
+	return ta.CountTxContext(context.Background())
+}
+
+// CountTxContext return query count with context
+func (ta *TableAgent) CountTxContext(ctx context.Context) (int, error) {
 	if query, args, err := ta.getCountAndArgs(); err != nil {
 		return 0, err
 	} else {
 		var count int
-		if err = ta.Agent.queryRowScanTx(query, &count, args...); err != nil {
+		if err = ta.Agent.queryRowScanTx(ctx, query, &count, args...); err != nil {
 			return 0, err
 		}
 		return count, nil
@@ -311,12 +446,17 @@ func (ta *TableAgent) CountTx() (int, error) {
 
 // Exists return query sql exists data
 func (ta *TableAgent) Exists() (bool, error) {
+	return ta.ExistsContext(context.Background())
+}
+
+// ExistsContext return query sql exists data with context
+func (ta *TableAgent) ExistsContext(ctx context.Context) (bool, error) {
 	if query, args, err := ta.getQueryAndArgs(); err != nil {
 		return false, err
 	} else {
 		query = getExistsSql(ta.Agent.DBType(), query)
 		var e string
-		if err = ta.Agent.queryRowScan(query, &e, args...); err != nil {
+		if err = ta.Agent.queryRowScan(ctx, nil, query, &e, args...); err != nil {
 			return false, err
 		}
 		return e == "Y", nil
@@ -325,12 +465,17 @@ func (ta *TableAgent) Exists() (bool, error) {
 
 // ExistsTx return query sql exists data
 func (ta *TableAgent) ExistsTx() (bool, error) {
+	return ta.ExistsTxContext(context.Background())
+}
+
+// ExistsTxContext return query sql exists data with context
+func (ta *TableAgent) ExistsTxContext(ctx context.Context) (bool, error) {
 	if query, args, err := ta.getQueryAndArgs(); err != nil {
 		return false, err
 	} else {
 		query = getExistsSql(ta.Agent.DBType(), query)
 		var e string
-		if err = ta.Agent.queryRowScanTx(query, &e, args...); err != nil {
+		if err = ta.Agent.queryRowScanTx(ctx, query, &e, args...); err != nil {
 			return false, err
 		}
 		return e == "Y", nil
@@ -339,33 +484,48 @@ func (ta *TableAgent) ExistsTx() (bool, error) {
 
 // Insert executes a query with db.Exec
 func (ta *TableAgent) Insert() (Result, error) {
+	return ta.InsertContext(context.Background())
+}
+
+// InsertContext executes a query with db.ExecContext
+func (ta *TableAgent) InsertContext(ctx context.Context) (Result, error) {
 	if query, args, err := ta.getInsert(); err != nil {
 		return nil, err
 	} else {
-		return ta.Agent.exec(query, args...)
+		return ta.Agent.exec(ctx, nil, query, args...)
 	}
 }
 
 // InsertTx executes a query with tx.Exec
 func (ta *TableAgent) InsertTx() (Result, error) {
+	return ta.InsertTxContext(context.Background())
+}
+
+// InsertTxContext executes a query with tx.ExecContext
+func (ta *TableAgent) InsertTxContext(ctx context.Context) (Result, error) {
 	if ta.Agent == nil {
 		return nil, errorStr(errorAgentNil)
 	}
 	if query, args, err := ta.getInsert(); err != nil {
 		return nil, err
 	} else {
-		return ta.Agent.execTx(query, args...)
+		return ta.Agent.execTx(ctx, query, args...)
 	}
 }
 
 // InsertWithLastInsertId return last insert id by QueryRow.Scan
 func (ta *TableAgent) InsertWithLastInsertId() (int, error) {
+	return ta.InsertWithLastInsertIdContext(context.Background())
+}
+
+// InsertWithLastInsertIdContext return last insert id by QueryRowContext.Scan
+func (ta *TableAgent) InsertWithLastInsertIdContext(ctx context.Context) (int, error) {
 	if query, args, err := ta.getInsert(); err != nil {
 		return 0, err
 	} else {
 		query = ta.getInsertWithLastInsertId(query)
 		var id int
-		if err = ta.Agent.queryRowScan(query, &id, args...); err != nil {
+		if err = ta.Agent.queryRowScan(ctx, nil, query, &id, args...); err != nil {
 			return 0, err
 		}
 		return id, nil
@@ -374,62 +534,193 @@ func (ta *TableAgent) InsertWithLastInsertId() (int, error) {
 
 // InsertTxWithLastInsertId return last insert id by QueryRow.Scan
 func (ta *TableAgent) InsertTxWithLastInsertId() (int, error) {
+	return ta.InsertTxWithLastInsertIdContext(context.Background())
+}
+
+// InsertTxWithLastInsertIdContext return last insert id by QueryRowContext.Scan
+func (ta *TableAgent) InsertTxWithLastInsertIdContext(ctx context.Context) (int, error) {
 	if query, args, err := ta.getInsert(); err != nil {
 		return 0, err
 	} else {
 		query = ta.getInsertWithLastInsertId(query)
 		var id int
-		if err = ta.Agent.queryRowScanTx(query, &id, args...); err != nil {
+		if err = ta.Agent.queryRowScanTx(ctx, query, &id, args...); err != nil {
 			return 0, err
 		}
 		return id, nil
 	}
 }
 
+// InsertBatch inserts rows by multi-row insert statements in a transaction, returns the Result of each statement
+// the rows are split by the max number of the bind parameters and rows of db Type, all rows must have the same columns
+func (ta *TableAgent) InsertBatch(rows []map[string]interface{}) ([]Result, error) {
+	return ta.InsertBatchContext(context.Background(), rows)
+}
+
+// InsertBatchContext inserts rows by multi-row insert statements with context in a transaction, returns the Result of each statement
+// the rows are split by the max number of the bind parameters and rows of db Type, all rows must have the same columns
+func (ta *TableAgent) InsertBatchContext(ctx context.Context, rows []map[string]interface{}) ([]Result, error) {
+	return ta.insertBatch(ctx, nil, rows)
+}
+
+// InsertBatchTx inserts rows by multi-row insert statements in the Agent transaction, returns the Result of each statement
+// the rows are split by the max number of the bind parameters and rows of db Type, all rows must have the same columns
+func (ta *TableAgent) InsertBatchTx(rows []map[string]interface{}) ([]Result, error) {
+	return ta.InsertBatchTxContext(context.Background(), rows)
+}
+
+// InsertBatchTxContext inserts rows by multi-row insert statements with context in the Agent transaction, returns the Result of each statement
+// the rows are split by the max number of the bind parameters and rows of db Type, all rows must have the same columns
+func (ta *TableAgent) InsertBatchTxContext(ctx context.Context, rows []map[string]interface{}) ([]Result, error) {
+	if ta.Agent == nil {
+		return nil, errorStr(errorAgentNil)
+	}
+	if tx, err := ta.Agent.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return ta.insertBatch(ctx, tx, rows)
+	}
+}
+
+// Upsert inserts Col, or updates updateCols of the row that conflicts on conflictCols
+// if updateCols is empty, the conflicting row is not changed, the conflictCols are not used by MySql which conflicts on any unique key
+func (ta *TableAgent) Upsert(conflictCols, updateCols []string) (Result, error) {
+	return ta.UpsertContext(context.Background(), conflictCols, updateCols)
+}
+
+// UpsertContext inserts Col with context, or updates updateCols of the row that conflicts on conflictCols
+// if updateCols is empty, the conflicting row is not changed, the conflictCols are not used by MySql which conflicts on any unique key
+func (ta *TableAgent) UpsertContext(ctx context.Context, conflictCols, updateCols []string) (Result, error) {
+	if query, args, err := ta.getUpsert(conflictCols, updateCols); err != nil {
+		return nil, err
+	} else {
+		return ta.Agent.exec(ctx, nil, query, args...)
+	}
+}
+
+// UpsertTx inserts Col in the Agent transaction, or updates updateCols of the row that conflicts on conflictCols
+// if updateCols is empty, the conflicting row is not changed, the conflictCols are not used by MySql which conflicts on any unique key
+func (ta *TableAgent) UpsertTx(conflictCols, updateCols []string) (Result, error) {
+	return ta.UpsertTxContext(context.Background(), conflictCols, updateCols)
+}
+
+// UpsertTxContext inserts Col with context in the Agent transaction, or updates updateCols of the row that conflicts on conflictCols
+// if updateCols is empty, the conflicting row is not changed, the conflictCols are not used by MySql which conflicts on any unique key
+func (ta *TableAgent) UpsertTxContext(ctx context.Context, conflictCols, updateCols []string) (Result, error) {
+	if ta.Agent == nil {
+		return nil, errorStr(errorAgentNil)
+	}
+	if query, args, err := ta.getUpsert(conflictCols, updateCols); err != nil {
+		return nil, err
+	} else {
+		return ta.Agent.execTx(ctx, query, args...)
+	}
+}
+
+// UpsertBatch upserts rows by multi-row upsert statements in a transaction, returns the Result of each statement
+// the rows are split by the max number of the bind parameters and rows of db Type, all rows must have the same columns
+// if updateCols is empty, the conflicting rows are not changed, the conflictCols are not used by MySql which conflicts on any unique key
+func (ta *TableAgent) UpsertBatch(rows []map[string]interface{}, conflictCols, updateCols []string) ([]Result, error) {
+	return ta.UpsertBatchContext(context.Background(), rows, conflictCols, updateCols)
+}
+
+// UpsertBatchContext upserts rows by multi-row upsert statements with context in a transaction, returns the Result of each statement
+// the rows are split by the max number of the bind parameters and rows of db Type, all rows must have the same columns
+// if updateCols is empty, the conflicting rows are not changed, the conflictCols are not used by MySql which conflicts on any unique key
+func (ta *TableAgent) UpsertBatchContext(ctx context.Context, rows []map[string]interface{}, conflictCols, updateCols []string) ([]Result, error) {
+	return ta.upsertBatch(ctx, nil, rows, conflictCols, updateCols)
+}
+
+// UpsertBatchTx upserts rows by multi-row upsert statements in the Agent transaction, returns the Result of each statement
+// the rows are split by the max number of the bind parameters and rows of db Type, all rows must have the same columns
+// if updateCols is empty, the conflicting rows are not changed, the conflictCols are not used by MySql which conflicts on any unique key
+func (ta *TableAgent) UpsertBatchTx(rows []map[string]interface{}, conflictCols, updateCols []string) ([]Result, error) {
+	return ta.UpsertBatchTxContext(context.Background(), rows, conflictCols, updateCols)
+}
+
+// UpsertBatchTxContext upserts rows by multi-row upsert statements with context in the Agent transaction, returns the Result of each statement
+// the rows are split by the max number of the bind parameters and rows of db Type, all rows must have the same columns
+// if updateCols is empty, the conflicting rows are not changed, the conflictCols are not used by MySql which conflicts on any unique key
+func (ta *TableAgent) UpsertBatchTxContext(ctx context.Context, rows []map[string]interface{}, conflictCols, updateCols []string) ([]Result, error) {
+	if ta.Agent == nil {
+		return nil, errorStr(errorAgentNil)
+	}
+	if tx, err := ta.Agent.currentTx(); err != nil {
+		return nil, err
+	} else {
+		return ta.upsertBatch(ctx, tx, rows, conflictCols, updateCols)
+	}
+}
+
 // Update executes a query with db.Exec
 func (ta *TableAgent) Update() (Result, error) {
+	return ta.UpdateContext(context.Background())
+}
+
+// UpdateContext executes a query with db.ExecContext
+func (ta *TableAgent) UpdateContext(ctx context.Context) (Result, error) {
 	if query, args, err := ta.getUpdate(); err != nil {
 		return nil, err
 	} else {
-		return ta.Agent.exec(query, args...)
+		return ta.Agent.exec(ctx, nil, query, args...)
 	}
 }
 
 // UpdateTx executes a query with tx.Exec
 func (ta *TableAgent) UpdateTx() (Result, error) {
+	return ta.UpdateTxContext(context.Background())
+}
+
+// UpdateTxContext executes a query with tx.ExecContext
+func (ta *TableAgent) UpdateTxContext(ctx context.Context) (Result, error) {
 	if ta.Agent == nil {
 		return nil, errorStr(errorAgentNil)
 	}
 	if query, args, err := ta.getUpdate(); err != nil {
 		return nil, err
 	} else {
-		return ta.Agent.execTx(query, args...)
+		return ta.Agent.execTx(ctx, query, args...)
 	}
 }
 
 // Delete executes a query with db.Exec
 func (ta *TableAgent) Delete() (Result, error) {
+	return ta.DeleteContext(context.Background())
+}
+
+// DeleteContext executes a query with db.ExecContext
+func (ta *TableAgent) DeleteContext(ctx context.Context) (Result, error) {
 	if query, args, err := ta.getDelete(); err != nil {
 		return nil, err
 	} else {
-		return ta.Agent.exec(query, args...)
+		return ta.Agent.exec(ctx, nil, query, args...)
 	}
 }
 
 // DeleteTx executes a query with tx.Exec
 func (ta *TableAgent) DeleteTx() (Result, error) {
+	return ta.DeleteTxContext(context.Background())
+}
+
+// DeleteTxContext executes a query with tx.ExecContext
+func (ta *TableAgent) DeleteTxContext(ctx context.Context) (Result, error) {
 	if ta.Agent == nil {
 		return nil, errorStr(errorAgentNil)
 	}
 	if query, args, err := ta.getDelete(); err != nil {
 		return nil, err
 	} else {
-		return ta.Agent.execTx(query, args...)
+		return ta.Agent.execTx(ctx, query, args...)
 	}
 }
 
 // Drop executes a query with db.Exec
 func (ta *TableAgent) Drop() (Result, error) {
+	return ta.DropContext(context.Background())
+}
+
+// DropContext executes a query with db.ExecContext
+func (ta *TableAgent) DropContext(ctx context.Context) (Result, error) {
 	if ta.Table == "" {
 		return nil, errorStr(errorTableEmpty)
 	}
@@ -439,11 +730,16 @@ func (ta *TableAgent) Drop() (Result, error) {
 			return nil, err
 		}
 	}
-	return ta.Agent.exec(fmt.Sprint("DROP TABLE ", ta.Table))
+	return ta.Agent.exec(ctx, nil, fmt.Sprint("DROP TABLE ", ta.Table))
 }
 
 // DropTx executes a query with tx.Exec
 func (ta *TableAgent) DropTx() (Result, error) {
+	return ta.DropTxContext(context.Background())
+}
+
+// DropTxContext executes a query with tx.ExecContext
+func (ta *TableAgent) DropTxContext(ctx context.Context) (Result, error) {
 	if ta.Table == "" {
 		return nil, errorStr(errorTableEmpty)
 	}
@@ -453,7 +749,7 @@ func (ta *TableAgent) DropTx() (Result, error) {
 			return nil, err
 		}
 	}
-	return ta.Agent.execTx(fmt.Sprint("DROP TABLE ", ta.Table))
+	return ta.Agent.execTx(ctx, fmt.Sprint("DROP TABLE ", ta.Table))
 }
 
 func (ta *TableAgent) getQueryAndArgs() (query string, args []interface{}, err error) {
@@ -556,12 +852,177 @@ func (ta *TableAgent) getInsert() (query string, args []interface{}, err error)
 	return query, args, nil
 }
 
+// insertBatch runs the batch insert statements in tx,
+// if tx is nil, they run in a new transaction which does not touch the Agent transaction
+func (ta *TableAgent) insertBatch(ctx context.Context, tx *Tx, rows []map[string]interface{}) ([]Result, error) {
+	if cols, err := ta.getBatchColumns(rows); err != nil {
+		return nil, err
+	} else {
+		return ta.execBatch(ctx, tx, rows, cols, func(d Dialect, n int) (string, error) {
+			return d.BatchInsertSql(ta.Table, cols, n), nil
+		})
+	}
+}
+
+// upsertBatch runs the batch upsert statements in tx,
+// if tx is nil, they run in a new transaction which does not touch the Agent transaction
+func (ta *TableAgent) upsertBatch(ctx context.Context, tx *Tx, rows []map[string]interface{}, conflictCols, updateCols []string) ([]Result, error) {
+	if cols, err := ta.getUpsertColumns(rows, conflictCols, updateCols); err != nil {
+		return nil, err
+	} else if d := ta.Agent.DBType().Dialect(); d == nil {
+		return nil, errorStr(errorUnknownSqlTypeForBatch)
+	} else if _, err = getUpsertSql(d, ta.Table, cols, conflictCols, updateCols, 1); err != nil {
+		return nil, err
+	} else {
+		return ta.execBatch(ctx, tx, rows, cols, func(d Dialect, n int) (string, error) {
+			return getUpsertSql(d, ta.Table, cols, conflictCols, updateCols, n)
+		})
+	}
+}
+
+// execBatch runs the statements of rows split by the max number of the bind parameters and rows in tx,
+// the query returns the statement of n rows, if tx is nil, they run in a new transaction
+func (ta *TableAgent) execBatch(ctx context.Context, tx *Tx, rows []map[string]interface{}, cols []string, query func(d Dialect, n int) (string, error)) (result []Result, err error) {
+	d := ta.Agent.DBType().Dialect()
+	if d == nil {
+		return nil, errorStr(errorUnknownSqlTypeForBatch)
+	}
+	if len(cols) > d.MaxParams() {
+		return nil, errorFmt(errorBatchRowParams, len(cols), d.MaxParams())
+	}
+	if tx == nil {
+		if tx, err = ta.Agent.BeginTxContext(ctx, nil); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				if e := tx.Rollback(); e != nil {
+					err = e
+				}
+			}
+		}()
+		if result, err = ta.execBatch(ctx, tx, rows, cols, query); err != nil {
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	size := 1
+	if n := d.MaxParams() / len(cols); n > 1 {
+		size = n
+	}
+	if n := d.MaxRows(); n > 0 && size > n {
+		size = n
+	}
+	result = make([]Result, 0, (len(rows)+size-1)/size)
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+		args := make([]interface{}, 0, (end-start)*len(cols))
+		for _, row := range rows[start:end] {
+			for _, col := range cols {
+				args = append(args, row[col])
+			}
+		}
+		var q string
+		if q, err = query(d, end-start); err != nil {
+			return nil, err
+		}
+		var res Result
+		if res, err = ta.Agent.exec(ctx, tx, q, args...); err != nil {
+			return nil, err
+		}
+		result = append(result, res)
+	}
+	return result, nil
+}
+
+// getBatchColumns returns the sorted columns of the first row, and checks all rows have the same columns
+func (ta *TableAgent) getBatchColumns(rows []map[string]interface{}) ([]string, error) {
+	if ta.Table == "" {
+		return nil, errorStr(errorTableEmpty)
+	}
+	if len(rows) <= 0 {
+		return nil, errorStr(errorBatchRowsEmpty)
+	}
+	if len(rows[0]) <= 0 {
+		return nil, errorStr(errorColNil)
+	}
+	if ta.Agent == nil {
+		var err error
+		if ta.Agent, err = GetAgent(ta.DSKey); err != nil {
+			return nil, err
+		}
+	}
+	cols := make([]string, 0, len(rows[0]))
+	for k := range rows[0] {
+		cols = append(cols, k)
+	}
+	sort.Strings(cols)
+	for i, row := range rows {
+		if len(row) != len(cols) {
+			return nil, errorFmt(errorBatchRowColumns, i)
+		}
+		for _, col := range cols {
+			if _, ok := row[col]; !ok {
+				return nil, errorFmt(errorBatchRowColumns, i)
+			}
+		}
+	}
+	return cols, nil
+}
+
+// getUpsert returns the upsert query and args of Col
+func (ta *TableAgent) getUpsert(conflictCols, updateCols []string) (query string, args []interface{}, err error) {
+	var cols []string
+	if cols, err = ta.getUpsertColumns([]map[string]interface{}{ta.Col}, conflictCols, updateCols); err != nil {
+		return "", nil, err
+	}
+	d := ta.Agent.DBType().Dialect()
+	if d == nil {
+		return "", nil, errorStr(errorUnknownSqlTypeForBatch)
+	}
+	if query, err = getUpsertSql(d, ta.Table, cols, conflictCols, updateCols, 1); err != nil {
+		return "", nil, err
+	}
+	args = make([]interface{}, len(cols))
+	for i, col := range cols {
+		args[i] = ta.Col[col]
+	}
+	return query, args, nil
+}
+
+// getUpsertSql returns the upsert query of d, or error if d needs the conflictCols
+func getUpsertSql(d Dialect, table string, cols, conflictCols, updateCols []string, n int) (string, error) {
+	if query := d.UpsertSql(table, cols, conflictCols, updateCols, n); query != "" {
+		return query, nil
+	}
+	return "", errorStr(errorUpsertConflictEmpty)
+}
+
+// getUpsertColumns returns the sorted columns of the first row, and checks conflictCols and updateCols are in them
+func (ta *TableAgent) getUpsertColumns(rows []map[string]interface{}, conflictCols, updateCols []string) ([]string, error) {
+	cols, err := ta.getBatchColumns(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range [][]string{conflictCols, updateCols} {
+		for _, col := range list {
+			if i := sort.SearchStrings(cols, col); i >= len(cols) || cols[i] != col {
+				return nil, errorFmt(errorUpsertColumn, col)
+			}
+		}
+	}
+	return cols, nil
+}
+
 func (ta *TableAgent) getInsertWithLastInsertId(query string) string {
-	switch ta.Agent.DBType() {
-	case MSSql:
-		return fmt.Sprint(query, "; SELECT SCOPE_IDENTITY()")
-	case PostgreSql:
-		return fmt.Sprint(query, " RETURNING id")
+	if d := ta.Agent.DBType().Dialect(); d != nil {
+		return d.LastInsertIdSql(query)
 	}
 	return query
 }
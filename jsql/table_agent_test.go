@@ -0,0 +1,210 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDialect_BatchInsertSql(t *testing.T) {
+	cols := []string{"A", "B"}
+	tests := []struct {
+		t   Type
+		out string
+	}{
+		{MySql, "INSERT INTO T (A, B) VALUES (?, ?), (?, ?)"},
+		{MSSql, "INSERT INTO T (A, B) VALUES (@p1, @p2), (@p3, @p4)"},
+		{PostgreSql, "INSERT INTO T (A, B) VALUES ($1, $2), ($3, $4)"},
+		{Sqlite, "INSERT INTO T (A, B) VALUES (?, ?), (?, ?)"},
+		{Oracle, "INSERT ALL INTO T (A, B) VALUES (:0, :1) INTO T (A, B) VALUES (:2, :3) SELECT 1 FROM DUAL"},
+	}
+	for _, v := range tests {
+		assert.Equal(t, v.out, v.t.Dialect().BatchInsertSql("T", cols, 2))
+	}
+}
+
+func TestTableAgent_InsertBatch(t *testing.T) {
+	rows := make([]map[string]interface{}, 1500)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"ID": i, "NAME": "a", "SEQ": i}
+	}
+	ta := &TableAgent{Agent: &Agent{db: testOpenDB(), t: MSSql}, Table: "T"}
+	testTakeExecs()
+	res, err := ta.InsertBatch(rows)
+	assert.Nil(t, err)
+	affected := make([]int64, len(res))
+	for i, r := range res {
+		affected[i], _ = r.RowsAffected()
+	}
+	// 2098 params of MSSql are 699 rows of 3 columns
+	assert.Equal(t, []int64{2097, 2097, 306}, affected)
+	execs := testTakeExecs()
+	assert.Equal(t, 5, len(execs))
+	assert.Equal(t, "BEGIN", execs[0])
+	assert.Equal(t, ta.Agent.t.Dialect().BatchInsertSql("T", []string{"ID", "NAME", "SEQ"}, 699), execs[1])
+	assert.Equal(t, ta.Agent.t.Dialect().BatchInsertSql("T", []string{"ID", "NAME", "SEQ"}, 102), execs[3])
+	assert.Equal(t, "COMMIT", execs[4])
+
+	// 1049 rows of 2 columns are limited to 1000 rows of MSSql
+	for _, row := range rows {
+		delete(row, "SEQ")
+	}
+	res, err = ta.InsertBatch(rows)
+	assert.Nil(t, err)
+	affected = make([]int64, len(res))
+	for i, r := range res {
+		affected[i], _ = r.RowsAffected()
+	}
+	assert.Equal(t, []int64{2000, 1000}, affected)
+	execs = testTakeExecs()
+	assert.Equal(t, 4, len(execs))
+	assert.Equal(t, ta.Agent.t.Dialect().BatchInsertSql("T", []string{"ID", "NAME"}, 1000), execs[1])
+	assert.Equal(t, ta.Agent.t.Dialect().BatchInsertSql("T", []string{"ID", "NAME"}, 500), execs[2])
+
+	tests := []struct {
+		ta   *TableAgent
+		rows []map[string]interface{}
+	}{
+		{&TableAgent{Agent: ta.Agent}, rows},
+		{ta, nil},
+		{ta, []map[string]interface{}{{}}},
+		{ta, []map[string]interface{}{{"ID": 1}, {"ID": 2, "NAME": "b"}}},
+		{ta, []map[string]interface{}{{"ID": 1, "SEQ": 1}, {"ID": 2, "NAME": "b"}}},
+	}
+	for _, v := range tests {
+		_, err = v.ta.InsertBatch(v.rows)
+		assert.NotNil(t, err)
+	}
+	assert.Nil(t, testTakeExecs())
+
+	// a row of 2099 columns is more than the 2098 params of MSSql, the transaction is not started
+	wide := make(map[string]interface{}, 2099)
+	for i := 0; i < 2099; i++ {
+		wide[fmt.Sprintf("C%d", i)] = i
+	}
+	_, err = ta.InsertBatch([]map[string]interface{}{wide})
+	assert.Equal(t, errorFmt(errorBatchRowParams, 2099, 2098), err)
+	_, err = ta.UpsertBatch([]map[string]interface{}{wide}, []string{"C0"}, nil)
+	assert.Equal(t, errorFmt(errorBatchRowParams, 2099, 2098), err)
+	assert.Nil(t, testTakeExecs())
+}
+
+func TestDialect_UpsertSql(t *testing.T) {
+	cols := []string{"A", "B", "C"}
+	a := []string{"A"}
+	tests := []struct {
+		t        Type
+		conflict []string
+		update   []string
+		out      string
+	}{
+		{MySql, a, []string{"B", "C"}, "INSERT INTO T (A, B, C) VALUES (?, ?, ?), (?, ?, ?) ON DUPLICATE KEY UPDATE B = VALUES(B), C = VALUES(C)"},
+		{MySql, nil, []string{"B"}, "INSERT INTO T (A, B, C) VALUES (?, ?, ?), (?, ?, ?) ON DUPLICATE KEY UPDATE B = VALUES(B)"},
+		{MySql, nil, nil, "INSERT INTO T (A, B, C) VALUES (?, ?, ?), (?, ?, ?) ON DUPLICATE KEY UPDATE A = A"},
+		{PostgreSql, a, []string{"B", "C"}, "INSERT INTO T (A, B, C) VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT (A) DO UPDATE SET B = EXCLUDED.B, C = EXCLUDED.C"},
+		{PostgreSql, a, nil, "INSERT INTO T (A, B, C) VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT (A) DO NOTHING"},
+		{PostgreSql, nil, nil, "INSERT INTO T (A, B, C) VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT DO NOTHING"},
+		{PostgreSql, nil, []string{"B"}, ""},
+		{Sqlite, a, []string{"B"}, "INSERT INTO T (A, B, C) VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT (A) DO UPDATE SET B = EXCLUDED.B"},
+		{MSSql, a, []string{"B", "C"}, "MERGE INTO T T1 USING (VALUES (@p1, @p2, @p3), (@p4, @p5, @p6)) S1 (A, B, C) ON (T1.A = S1.A) " +
+			"WHEN MATCHED THEN UPDATE SET T1.B = S1.B, T1.C = S1.C WHEN NOT MATCHED THEN INSERT (A, B, C) VALUES (S1.A, S1.B, S1.C);"},
+		{MSSql, a, nil, "MERGE INTO T T1 USING (VALUES (@p1, @p2, @p3), (@p4, @p5, @p6)) S1 (A, B, C) ON (T1.A = S1.A) " +
+			"WHEN NOT MATCHED THEN INSERT (A, B, C) VALUES (S1.A, S1.B, S1.C);"},
+		{MSSql, nil, nil, ""},
+		{Oracle, a, []string{"B"}, "MERGE INTO T T1 USING (SELECT :0 AS A, :1 AS B, :2 AS C FROM DUAL UNION ALL SELECT :3 AS A, :4 AS B, :5 AS C FROM DUAL) S1 " +
+			"ON (T1.A = S1.A) WHEN MATCHED THEN UPDATE SET T1.B = S1.B WHEN NOT MATCHED THEN INSERT (A, B, C) VALUES (S1.A, S1.B, S1.C)"},
+		{Oracle, nil, []string{"B"}, ""},
+	}
+	for _, v := range tests {
+		assert.Equal(t, v.out, v.t.Dialect().UpsertSql("T", cols, v.conflict, v.update, 2))
+	}
+}
+
+func TestTableAgent_Upsert(t *testing.T) {
+	ta := &TableAgent{Agent: &Agent{db: testOpenDB(), t: PostgreSql}, Table: "T", Col: map[string]interface{}{"ID": 1, "NAME": "a"}}
+	testTakeExecs()
+	_, err := ta.Upsert([]string{"ID"}, []string{"NAME"})
+	assert.Nil(t, err)
+	query := "INSERT INTO T (ID, NAME) VALUES ($1, $2) ON CONFLICT (ID) DO UPDATE SET NAME = EXCLUDED.NAME"
+	assert.Equal(t, []string{query}, testTakeExecs())
+	args := testTakeArgs(query)
+	assert.Equal(t, 2, len(args))
+	assert.EqualValues(t, 1, args[0])
+	assert.EqualValues(t, "a", args[1])
+
+	tests := []struct {
+		conflict []string
+		update   []string
+	}{
+		{nil, []string{"NAME"}},
+		{[]string{"CODE"}, nil},
+		{[]string{"ID"}, []string{"CODE"}},
+	}
+	for _, v := range tests {
+		_, err = ta.Upsert(v.conflict, v.update)
+		assert.NotNil(t, err)
+	}
+	_, err = (&TableAgent{Agent: ta.Agent, Table: "T"}).Upsert([]string{"ID"}, nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, testTakeExecs())
+
+	// MySql does not need the conflict columns
+	ta.Agent.t = MySql
+	_, err = ta.Upsert(nil, []string{"NAME"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"INSERT INTO T (ID, NAME) VALUES (?, ?) ON DUPLICATE KEY UPDATE NAME = VALUES(NAME)"}, testTakeExecs())
+}
+
+func TestTableAgent_UpsertBatch(t *testing.T) {
+	rows := make([]map[string]interface{}, 1500)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"ID": i, "NAME": "a", "SEQ": i}
+	}
+	ta := &TableAgent{Agent: &Agent{db: testOpenDB(), t: MSSql}, Table: "T"}
+	testTakeExecs()
+	res, err := ta.UpsertBatch(rows, []string{"ID"}, nil)
+	assert.Nil(t, err)
+	affected := make([]int64, len(res))
+	for i, r := range res {
+		affected[i], _ = r.RowsAffected()
+	}
+	assert.Equal(t, []int64{2097, 2097, 306}, affected)
+	execs := testTakeExecs()
+	assert.Equal(t, 5, len(execs))
+	assert.Equal(t, "BEGIN", execs[0])
+	assert.Equal(t, ta.Agent.t.Dialect().UpsertSql("T", []string{"ID", "NAME", "SEQ"}, []string{"ID"}, nil, 699), execs[1])
+	assert.Equal(t, "COMMIT", execs[4])
+
+	_, err = ta.UpsertBatch(rows, []string{"ID"}, []string{"CODE"})
+	assert.NotNil(t, err)
+	_, err = ta.UpsertBatch(nil, []string{"ID"}, nil)
+	assert.NotNil(t, err)
+	_, err = ta.UpsertBatch(rows, nil, nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, testTakeExecs())
+}
+
+func TestTableAgent_Query_LiteralNamedParam(t *testing.T) {
+	ta := &TableAgent{Agent: &Agent{db: testOpenDB(), t: MySql}, Table: "T", SelStr: "'@{ID}' AS A"}
+	ta.Equal("ID", 1)
+	query := "SELECT '@{ID}' AS A FROM T WHERE 1 = 1 AND ID = ?"
+	testSetQuery(query, []testColumn{{name: "A", dbType: "VARCHAR"}}, []driver.Value{"@{ID}"})
+	res, err := ta.Query()
+	assert.Nil(t, err)
+	assert.Equal(t, "@{ID}", res.(ColumnResult).GetString(0, "A"))
+	assert.Equal(t, []driver.Value{int64(1)}, testTakeArgs(query))
+	// the literal survives in the raw query with positional or named args
+	res, err = ta.Agent.QueryWithSql(query, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "@{ID}", res.(ColumnResult).GetString(0, "A"))
+	assert.Equal(t, []driver.Value{int64(1)}, testTakeArgs(query))
+	res, err = ta.Agent.QueryWithSql("SELECT '@{ID}' AS A FROM T WHERE 1 = 1 AND ID = @{ID}", map[string]interface{}{"ID": 2})
+	assert.Nil(t, err)
+	assert.Equal(t, "@{ID}", res.(ColumnResult).GetString(0, "A"))
+	assert.Equal(t, []driver.Value{int64(2)}, testTakeArgs(query))
+}
@@ -9,18 +9,28 @@ import "strings"
 type tag string
 
 const (
-	tagDao     tag = "dao"
-	tagText    tag = "text"
-	tagSelect  tag = "select"
-	tagInsert  tag = "insert"
-	tagUpdate  tag = "update"
-	tagDelete  tag = "delete"
-	tagOther   tag = "other"
-	tagIf      tag = "if"
-	tagForeach tag = "foreach"
-	tagWhere   tag = "where"
-	tagOrderBy tag = "orderBy"
-	tagUnknown tag = "Unknown"
+	tagDao        tag = "dao"
+	tagText       tag = "text"
+	tagSelect     tag = "select"
+	tagInsert     tag = "insert"
+	tagUpdate     tag = "update"
+	tagDelete     tag = "delete"
+	tagOther      tag = "other"
+	tagSql        tag = "sql"
+	tagInclude    tag = "include"
+	tagProperty   tag = "property"
+	tagIdentifier tag = "identifier"
+	tagIf         tag = "if"
+	tagForeach    tag = "foreach"
+	tagWhere      tag = "where"
+	tagSet        tag = "set"
+	tagTrim       tag = "trim"
+	tagOrderBy    tag = "orderBy"
+	tagCount      tag = "count"
+	tagChoose     tag = "choose"
+	tagWhen       tag = "when"
+	tagOtherwise  tag = "otherwise"
+	tagUnknown    tag = "Unknown"
 )
 
 func (t tag) String() string {
@@ -43,14 +53,34 @@ func parseTag(str string) tag {
 		return tagDelete
 	case "other":
 		return tagOther
+	case "sql":
+		return tagSql
+	case "include":
+		return tagInclude
+	case "property":
+		return tagProperty
+	case "identifier":
+		return tagIdentifier
 	case "if":
 		return tagIf
 	case "foreach":
 		return tagForeach
 	case "where":
 		return tagWhere
+	case "set":
+		return tagSet
+	case "trim":
+		return tagTrim
 	case "orderby":
 		return tagOrderBy
+	case "count":
+		return tagCount
+	case "choose":
+		return tagChoose
+	case "when":
+		return tagWhen
+	case "otherwise":
+		return tagOtherwise
 	default:
 		return tagUnknown
 	}
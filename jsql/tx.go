@@ -0,0 +1,404 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// executor is implemented by both *sql.DB and *sql.Tx
+type executor interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// Tx is a transaction handle returned by Agent.BeginTx
+// all statements of a Tx are executed in the same transaction
+type Tx struct {
+	agent     *Agent
+	tx        *sql.Tx
+	savepoint int
+}
+
+// Agent returns the Agent that started this transaction
+func (t *Tx) Agent() *Agent {
+	return t.agent
+}
+
+// Tx returns this transaction *sql.Tx
+func (t *Tx) Tx() *sql.Tx {
+	return t.tx
+}
+
+// Commit same as sql.Tx.Commit
+func (t *Tx) Commit() error {
+	if t.tx == nil {
+		return errorStr(errorDbNotBegin)
+	}
+	return t.tx.Commit()
+}
+
+// Rollback same as sql.Tx.Rollback
+func (t *Tx) Rollback() error {
+	if t.tx == nil {
+		return errorStr(errorDbNotBegin)
+	}
+	return t.tx.Rollback()
+}
+
+// UseTx start a nested transaction block with a savepoint,
+// return error will roll back to the savepoint, otherwise the savepoint is released
+func (t *Tx) UseTx(f func() error) error {
+	return t.UseTxContext(context.Background(), f)
+}
+
+// UseTxContext start a nested transaction block with context and a savepoint,
+// return error will roll back to the savepoint, otherwise the savepoint is released
+func (t *Tx) UseTxContext(ctx context.Context, f func() error) (err error) {
+	t.savepoint++
+	var savepoint, rollback, release string
+	if savepoint, rollback, release, err = getSavepointSql(t.agent.t, fmt.Sprint("JSQL_SP", t.savepoint)); err != nil {
+		return err
+	}
+	if _, err = t.ExecWithSqlContext(ctx, savepoint); err != nil {
+		return err
+	}
+	if err = f(); err != nil {
+		if _, e := t.ExecWithSqlContext(ctx, rollback); e != nil {
+			fmt.Println(e)
+		}
+		return err
+	}
+	if release != "" {
+		if _, err = t.ExecWithSqlContext(ctx, release); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Query executes a query that returns Result
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query, or result struct point
+func (t *Tx) Query(id string, args ...interface{}) (Result, error) {
+	return t.QueryContext(context.Background(), id, args...)
+}
+
+// QueryContext executes a query with context that returns Result
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query, or result struct point
+func (t *Tx) QueryContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return t.agent.queryOps(ctx, t, false, id, args...)
+}
+
+// QueryWithSql executes a query that returns Result
+func (t *Tx) QueryWithSql(query string, cond ...interface{}) (Result, error) {
+	return t.QueryWithSqlContext(context.Background(), query, cond...)
+}
+
+// QueryWithSqlContext executes a query with context that returns Result
+func (t *Tx) QueryWithSqlContext(ctx context.Context, query string, cond ...interface{}) (Result, error) {
+	return t.agent.queryRawSql(ctx, t, false, query, cond...)
+}
+
+// QueryPrepare creates a prepared statement for later queries or executions
+func (t *Tx) QueryPrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.QueryPrepareContext(context.Background(), id, param, args...)
+}
+
+// QueryPrepareContext creates a prepared statement with context for later queries or executions
+func (t *Tx) QueryPrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.agent.queryPrepareOps(ctx, t, false, id, param, args...)
+}
+
+// QueryRow executes a query that is expected to return at most one row
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query, or result struct point
+func (t *Tx) QueryRow(id string, args ...interface{}) (Result, error) {
+	return t.QueryRowContext(context.Background(), id, args...)
+}
+
+// QueryRowContext executes a query with context that is expected to return at most one row
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query, or result struct point
+func (t *Tx) QueryRowContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return t.agent.queryOps(ctx, t, true, id, args...)
+}
+
+// QueryRowWithSql executes a query that is expected to return at most one row
+func (t *Tx) QueryRowWithSql(query string, cond ...interface{}) (Result, error) {
+	return t.QueryRowWithSqlContext(context.Background(), query, cond...)
+}
+
+// QueryRowWithSqlContext executes a query with context that is expected to return at most one row
+func (t *Tx) QueryRowWithSqlContext(ctx context.Context, query string, cond ...interface{}) (Result, error) {
+	return t.agent.queryRawSql(ctx, t, true, query, cond...)
+}
+
+// QueryRowPrepare creates a prepared statement for later queries or executions
+func (t *Tx) QueryRowPrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.QueryRowPrepareContext(context.Background(), id, param, args...)
+}
+
+// QueryRowPrepareContext creates a prepared statement with context for later queries or executions
+func (t *Tx) QueryRowPrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.agent.queryPrepareOps(ctx, t, true, id, param, args...)
+}
+
+// QueryIter executes a query that returns Rows to read one row at a time
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query
+func (t *Tx) QueryIter(id string, args ...interface{}) (*Rows, error) {
+	return t.QueryIterContext(context.Background(), id, args...)
+}
+
+// QueryIterContext executes a query with context that returns Rows to read one row at a time
+// the id are for xml select tag id
+// the args are for any placeholder parameters in the query
+func (t *Tx) QueryIterContext(ctx context.Context, id string, args ...interface{}) (*Rows, error) {
+	return t.agent.queryIterOps(ctx, t, id, args...)
+}
+
+// QueryIterWithSql executes a query that returns Rows to read one row at a time
+func (t *Tx) QueryIterWithSql(query string, cond ...interface{}) (*Rows, error) {
+	return t.QueryIterWithSqlContext(context.Background(), query, cond...)
+}
+
+// QueryIterWithSqlContext executes a query with context that returns Rows to read one row at a time
+func (t *Tx) QueryIterWithSqlContext(ctx context.Context, query string, cond ...interface{}) (*Rows, error) {
+	return t.agent.queryIterRawSql(ctx, t, query, cond...)
+}
+
+// QueryPage executes a query that returns Result
+// the id are for xml select tag id
+// the start and end are for query start row and end row
+// the args are for any placeholder parameters in the query, or result struct point
+func (t *Tx) QueryPage(id string, start, end int64, args ...interface{}) (Result, error) {
+	return t.QueryPageContext(context.Background(), id, start, end, args...)
+}
+
+// QueryPageContext executes a query with context that returns Result
+// the id are for xml select tag id
+// the start and end are for query start row and end row
+// the args are for any placeholder parameters in the query, or result struct point
+func (t *Tx) QueryPageContext(ctx context.Context, id string, start, end int64, args ...interface{}) (Result, error) {
+	return t.agent.queryPage(ctx, t, id, start, end, args...)
+}
+
+// QueryPageWithSql executes a query that returns Result
+// the start and end are for query start row and end row
+func (t *Tx) QueryPageWithSql(query, order string, start, end int64, args ...interface{}) (Result, error) {
+	return t.QueryPageWithSqlContext(context.Background(), query, order, start, end, args...)
+}
+
+// QueryPageWithSqlContext executes a query with context that returns Result
+// the start and end are for query start row and end row
+func (t *Tx) QueryPageWithSqlContext(ctx context.Context, query, order string, start, end int64, args ...interface{}) (Result, error) {
+	return t.agent.queryPageRawSql(ctx, t, query, order, start, end, args...)
+}
+
+// QueryPaging executes a page number query that returns Result with PageInfo
+// the id are for xml select tag id
+// the p is the page number and page size
+// the args are for any placeholder parameters in the query, or result struct point
+func (t *Tx) QueryPaging(id string, p Paging, args ...interface{}) (Result, error) {
+	return t.QueryPagingContext(context.Background(), id, p, args...)
+}
+
+// QueryPagingContext executes a page number query with context that returns Result with PageInfo
+// the id are for xml select tag id
+// the p is the page number and page size
+// the args are for any placeholder parameters in the query, or result struct point
+func (t *Tx) QueryPagingContext(ctx context.Context, id string, p Paging, args ...interface{}) (Result, error) {
+	return t.agent.queryPaging(ctx, t, id, p, args...)
+}
+
+// QueryPagingWithSql executes a page number query that returns Result with PageInfo
+// the p is the page number and page size
+func (t *Tx) QueryPagingWithSql(query, order string, p Paging, args ...interface{}) (Result, error) {
+	return t.QueryPagingWithSqlContext(context.Background(), query, order, p, args...)
+}
+
+// QueryPagingWithSqlContext executes a page number query with context that returns Result with PageInfo
+// the p is the page number and page size
+func (t *Tx) QueryPagingWithSqlContext(ctx context.Context, query, order string, p Paging, args ...interface{}) (Result, error) {
+	return t.agent.queryPagingRawSql(ctx, t, query, order, p, args...)
+}
+
+// QueryKeyset executes a keyset pagination query that returns Result
+// the id are for xml select tag id
+// the k is the cursor of the page, the ORDER BY of the select tag is replaced by the k.Column
+// the args are for any placeholder parameters in the query, or result struct point
+func (t *Tx) QueryKeyset(id string, k Keyset, args ...interface{}) (Result, error) {
+	return t.QueryKeysetContext(context.Background(), id, k, args...)
+}
+
+// QueryKeysetContext executes a keyset pagination query with context that returns Result
+// the id are for xml select tag id
+// the k is the cursor of the page, the ORDER BY of the select tag is replaced by the k.Column
+// the args are for any placeholder parameters in the query, or result struct point
+func (t *Tx) QueryKeysetContext(ctx context.Context, id string, k Keyset, args ...interface{}) (Result, error) {
+	return t.agent.queryKeyset(ctx, t, id, k, args...)
+}
+
+// QueryKeysetWithSql executes a keyset pagination query that returns Result
+// the k is the cursor of the page, the query should not contain ORDER BY
+func (t *Tx) QueryKeysetWithSql(query string, k Keyset, args ...interface{}) (Result, error) {
+	return t.QueryKeysetWithSqlContext(context.Background(), query, k, args...)
+}
+
+// QueryKeysetWithSqlContext executes a keyset pagination query with context that returns Result
+// the k is the cursor of the page, the query should not contain ORDER BY
+func (t *Tx) QueryKeysetWithSqlContext(ctx context.Context, query string, k Keyset, args ...interface{}) (Result, error) {
+	return t.agent.queryKeysetRawSql(ctx, t, query, k, args...)
+}
+
+// Count return query count
+func (t *Tx) Count(id string, args ...interface{}) (int, error) {
+	return t.CountContext(context.Background(), id, args...)
+}
+
+// CountContext return query count with context
+func (t *Tx) CountContext(ctx context.Context, id string, args ...interface{}) (int, error) {
+	return t.agent.count(ctx, t, id, args...)
+}
+
+// Exists return query sql exists data
+func (t *Tx) Exists(id string, args ...interface{}) (bool, error) {
+	return t.ExistsContext(context.Background(), id, args...)
+}
+
+// ExistsContext return query sql exists data with context
+func (t *Tx) ExistsContext(ctx context.Context, id string, args ...interface{}) (bool, error) {
+	return t.agent.exists(ctx, t, id, args...)
+}
+
+// ExecWithSql executes a query with tx.Exec
+func (t *Tx) ExecWithSql(query string, cond ...interface{}) (Result, error) {
+	return t.ExecWithSqlContext(context.Background(), query, cond...)
+}
+
+// ExecWithSqlContext executes a query with tx.ExecContext
+func (t *Tx) ExecWithSqlContext(ctx context.Context, query string, cond ...interface{}) (Result, error) {
+	return t.agent.execRawSql(ctx, t, query, cond...)
+}
+
+// Insert executes a query with tx.Exec
+func (t *Tx) Insert(id string, args ...interface{}) (Result, error) {
+	return t.InsertContext(context.Background(), id, args...)
+}
+
+// InsertContext executes a query with tx.ExecContext
+func (t *Tx) InsertContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return t.agent.execOps(ctx, t, Insert, id, args...)
+}
+
+// InsertPrepare creates a prepared statement for later queries or executions
+func (t *Tx) InsertPrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.InsertPrepareContext(context.Background(), id, param, args...)
+}
+
+// InsertPrepareContext creates a prepared statement with context for later queries or executions
+func (t *Tx) InsertPrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.agent.execPrepareOps(ctx, t, Insert, id, param, args...)
+}
+
+// InsertWithLastInsertId return last insert id by QueryRow.Scan
+func (t *Tx) InsertWithLastInsertId(id string, args ...interface{}) (int, error) {
+	return t.InsertWithLastInsertIdContext(context.Background(), id, args...)
+}
+
+// InsertWithLastInsertIdContext return last insert id by QueryRowContext.Scan
+func (t *Tx) InsertWithLastInsertIdContext(ctx context.Context, id string, args ...interface{}) (int, error) {
+	return t.agent.insertWithLastInsertId(ctx, t, id, args...)
+}
+
+// Update executes a query with tx.Exec
+func (t *Tx) Update(id string, args ...interface{}) (Result, error) {
+	return t.UpdateContext(context.Background(), id, args...)
+}
+
+// UpdateContext executes a query with tx.ExecContext
+func (t *Tx) UpdateContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return t.agent.execOps(ctx, t, Update, id, args...)
+}
+
+// UpdatePrepare creates a prepared statement for later queries or executions
+func (t *Tx) UpdatePrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.UpdatePrepareContext(context.Background(), id, param, args...)
+}
+
+// UpdatePrepareContext creates a prepared statement with context for later queries or executions
+func (t *Tx) UpdatePrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.agent.execPrepareOps(ctx, t, Update, id, param, args...)
+}
+
+// Delete executes a query with tx.Exec
+func (t *Tx) Delete(id string, args ...interface{}) (Result, error) {
+	return t.DeleteContext(context.Background(), id, args...)
+}
+
+// DeleteContext executes a query with tx.ExecContext
+func (t *Tx) DeleteContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return t.agent.execOps(ctx, t, Delete, id, args...)
+}
+
+// DeletePrepare creates a prepared statement for later queries or executions
+func (t *Tx) DeletePrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.DeletePrepareContext(context.Background(), id, param, args...)
+}
+
+// DeletePrepareContext creates a prepared statement with context for later queries or executions
+func (t *Tx) DeletePrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.agent.execPrepareOps(ctx, t, Delete, id, param, args...)
+}
+
+// Other executes a query with tx.Exec
+func (t *Tx) Other(id string, args ...interface{}) (Result, error) {
+	return t.OtherContext(context.Background(), id, args...)
+}
+
+// OtherContext executes a query with tx.ExecContext
+func (t *Tx) OtherContext(ctx context.Context, id string, args ...interface{}) (Result, error) {
+	return t.agent.execOps(ctx, t, Other, id, args...)
+}
+
+// OtherPrepare creates a prepared statement for later queries or executions
+func (t *Tx) OtherPrepare(id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.OtherPrepareContext(context.Background(), id, param, args...)
+}
+
+// OtherPrepareContext creates a prepared statement with context for later queries or executions
+func (t *Tx) OtherPrepareContext(ctx context.Context, id string, param map[string]interface{}, args ...[]interface{}) ([]Result, error) {
+	return t.agent.execPrepareOps(ctx, t, Other, id, param, args...)
+}
+
+// Tables returns table name list
+// or you can use args input query statement
+func (t *Tx) Tables(args ...interface{}) ([]string, error) {
+	return t.TablesContext(context.Background(), args...)
+}
+
+// TablesContext returns table name list with context
+// or you can use args input query statement
+func (t *Tx) TablesContext(ctx context.Context, args ...interface{}) ([]string, error) {
+	return t.agent.tables(ctx, t, args...)
+}
+
+// TableSchema return table schema
+// or you can use args input query statement
+func (t *Tx) TableSchema(table string, args ...interface{}) ([]TableSchema, error) {
+	return t.TableSchemaContext(context.Background(), table, args...)
+}
+
+// TableSchemaContext return table schema with context
+// or you can use args input query statement
+func (t *Tx) TableSchemaContext(ctx context.Context, table string, args ...interface{}) ([]TableSchema, error) {
+	return t.agent.tableSchema(ctx, t, table, args...)
+}
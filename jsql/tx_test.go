@@ -0,0 +1,112 @@
+// Copyright 2022 JaJa All rights reserved.
+// Use of this source code is governed by a MIT-style.
+// license that can be found in the LICENSE file.
+
+package jsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAgent_BeginTx(t *testing.T) {
+	a := &Agent{}
+	tx, err := a.BeginTx(nil)
+	assert.Nil(t, tx)
+	assert.Equal(t, errorStr(errorDBNil), err)
+	assert.Nil(t, a.Tx())
+	_, err = a.QueryTx("id")
+	assert.Equal(t, errorStr(errorDbNotBegin), err)
+}
+
+func TestAgent_Executor(t *testing.T) {
+	a := &Agent{}
+	_, err := a.executor(nil)
+	assert.Equal(t, errorStr(errorDBNil), err)
+	_, err = a.executor(&Tx{agent: a})
+	assert.Equal(t, errorStr(errorDbNotBegin), err)
+}
+
+func TestAgent_UseTx_Savepoint(t *testing.T) {
+	errInner := errors.New("inner")
+	tests := []struct {
+		t     Type
+		inner error
+		execs []string
+	}{
+		{MySql, nil, []string{"BEGIN", "SAVEPOINT JSQL_SP1", "UPDATE T SET A = 1", "RELEASE SAVEPOINT JSQL_SP1", "COMMIT"}},
+		{PostgreSql, nil, []string{"BEGIN", "SAVEPOINT JSQL_SP1", "UPDATE T SET A = 1", "RELEASE SAVEPOINT JSQL_SP1", "COMMIT"}},
+		{MSSql, nil, []string{"BEGIN", "SAVE TRANSACTION JSQL_SP1", "UPDATE T SET A = 1", "COMMIT"}},
+		{Oracle, nil, []string{"BEGIN", "SAVEPOINT JSQL_SP1", "UPDATE T SET A = 1", "COMMIT"}},
+		{MySql, errInner, []string{"BEGIN", "SAVEPOINT JSQL_SP1", "UPDATE T SET A = 1", "ROLLBACK TO SAVEPOINT JSQL_SP1", "COMMIT"}},
+		{MSSql, errInner, []string{"BEGIN", "SAVE TRANSACTION JSQL_SP1", "UPDATE T SET A = 1", "ROLLBACK TRANSACTION JSQL_SP1", "COMMIT"}},
+	}
+	for _, v := range tests {
+		a := &Agent{db: testOpenDB(), t: v.t}
+		testTakeExecs()
+		err := a.UseTx(func() error {
+			err := a.UseTx(func() error {
+				if _, err := a.ExecTxWithSql("UPDATE T SET A = 1"); err != nil {
+					return err
+				}
+				return v.inner
+			})
+			assert.Equal(t, v.inner, err)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, v.execs, testTakeExecs(), v.t.DriverName())
+		assert.Nil(t, a.Tx())
+	}
+}
+
+func TestAgent_BeginTx_Exec(t *testing.T) {
+	a := &Agent{db: testOpenDB(), t: MySql}
+	testTakeExecs()
+	tx, err := a.BeginTx(&sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true})
+	assert.Nil(t, err)
+	assert.Equal(t, driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelSerializable), ReadOnly: true}, testTakeTxOptions())
+	// the Tx is not kept by the Agent
+	assert.Nil(t, a.Tx())
+	_, err = tx.ExecWithSql("UPDATE T SET A = ?", 1)
+	assert.Nil(t, err)
+	_, err = tx.ExecWithSql("UPDATE T SET B = ?", 2)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Commit())
+	assert.Equal(t, []string{"BEGIN", "UPDATE T SET A = ?", "UPDATE T SET B = ?", "COMMIT"}, testTakeExecs())
+	assert.Equal(t, sql.ErrTxDone, tx.Commit())
+
+	tx, err = a.BeginTx(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, driver.TxOptions{}, testTakeTxOptions())
+	assert.Nil(t, tx.Rollback())
+	assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, testTakeExecs())
+}
+
+func TestAgent_QueryPageWithSql_Begin(t *testing.T) {
+	pageQuery := "SELECT * FROM (SELECT * FROM T2 WHERE ID > $1) AS TBS1 ORDER BY ID LIMIT $2 OFFSET $3"
+	countQuery := "SELECT COUNT(1) AS TOTALRECORD FROM (SELECT * FROM T2 WHERE ID > $1) DATA"
+	testSetQuery(pageQuery, []testColumn{{name: "id", dbType: "INT8"}}, []driver.Value{int64(6)})
+	testSetQuery(countQuery, []testColumn{{name: "totalrecord", dbType: "INT8"}}, []driver.Value{int64(11)})
+	a := &Agent{db: testOpenDB(), t: PostgreSql}
+	_, err := a.Begin()
+	assert.Nil(t, err)
+	tx := a.Tx()
+	testTakeExecs()
+	// QueryPage runs in its own transaction and keeps the Agent transaction
+	_, err = a.QueryPageWithSql("SELECT * FROM T2 WHERE ID > $1", "ID", 6, 10, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, tx, a.Tx())
+	assert.Equal(t, []string{"BEGIN", "COMMIT"}, testTakeExecs())
+	// QueryPageTx runs in the Agent transaction
+	_, err = a.QueryPageTxWithSql("SELECT * FROM T2 WHERE ID > $1", "ID", 6, 10, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, tx, a.Tx())
+	assert.Nil(t, testTakeExecs())
+	assert.Nil(t, a.Commit())
+	assert.Equal(t, []string{"COMMIT"}, testTakeExecs())
+	assert.Nil(t, a.Tx())
+}
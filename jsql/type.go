@@ -6,7 +6,6 @@ package jsql
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 )
 
@@ -18,50 +17,47 @@ const (
 	MSSql
 	Oracle
 	PostgreSql
+	Sqlite
 )
 
 // DriverName returns sql driver name
 func (t Type) DriverName() string {
-	switch t {
-	case MySql:
-		return "mysql"
-	case MSSql:
-		return "sqlserver"
-	case Oracle:
-		return "godror"
-	case PostgreSql:
-		return "postgres"
+	if d := t.Dialect(); d != nil {
+		return d.DriverName()
 	}
 	return ""
 }
 
 // Param returns query params string of db Type
 func (t Type) Param(i int) string {
-	switch t {
-	case MySql:
-		fallthrough
-	default:
-		return "?"
-	case MSSql:
-		return fmt.Sprint("@p", strconv.FormatInt(int64(i+1), 10))
-	case Oracle:
-		return fmt.Sprint(":", strconv.FormatInt(int64(i), 10))
-	case PostgreSql:
-		return fmt.Sprint("$", strconv.FormatInt(int64(i+1), 10))
+	if d := t.Dialect(); d != nil {
+		return d.Param(i)
 	}
+	return "?"
+}
+
+// QuoteIdentifier returns the quoted identifier of name of db Type
+func (t Type) QuoteIdentifier(name string) string {
+	if d := t.Dialect(); d != nil {
+		return d.QuoteIdentifier(name)
+	}
+	return fmt.Sprint(`"`, strings.ReplaceAll(name, `"`, `""`), `"`)
+}
+
+// IsRetryable reports whether err is a deadlock or serialization failure of db Type,
+// the transaction of such error can be retried from the beginning
+func (t Type) IsRetryable(err error) bool {
+	if d := t.Dialect(); d != nil {
+		return d.IsRetryable(err)
+	}
+	return false
 }
 
 // ParseDBType takes a string db Type and returns the db Type constant.
+// the string can be the name of any Dialect registered by RegisterDialect
 func ParseDBType(t string) (Type, error) {
-	switch strings.ToLower(t) {
-	case "mysql":
-		return MySql, nil
-	case "mssql":
-		return MSSql, nil
-	case "oracle":
-		return Oracle, nil
-	case "postgresql":
-		return PostgreSql, nil
+	if dt, ok := lookupDialect(t); ok {
+		return dt, nil
 	}
 	return Unknown, errorFmt(errorNotValidDbType, t)
 }
@@ -19,6 +19,7 @@ func TestType_DriverName(t *testing.T) {
 		{MSSql, "sqlserver"},
 		{Oracle, "godror"},
 		{PostgreSql, "postgres"},
+		{Sqlite, "sqlite3"},
 		{Unknown, ""},
 	}
 	for _, v := range tests {
@@ -36,6 +37,7 @@ func TestType_Param(t *testing.T) {
 		{MSSql, "@p1"},
 		{Oracle, ":0"},
 		{PostgreSql, "$1"},
+		{Sqlite, "?"},
 		{Unknown, "?"},
 	}
 	for _, v := range tests {
@@ -53,6 +55,7 @@ func TestParseDBType(t *testing.T) {
 		{"MSSql", MSSql},
 		{"Oracle", Oracle},
 		{"PostgreSql", PostgreSql},
+		{"Sqlite", Sqlite},
 		{"Unknown", Unknown},
 	}
 	for _, v := range tests {